@@ -0,0 +1,125 @@
+package schemaregistry
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Credentials authenticate the requests to the registry.
+type Credentials struct {
+	// Username and Password are the HTTP Basic credentials. They're used if Username isn't empty.
+	Username string
+	Password string
+
+	// BearerToken is sent in the Authorization header as a bearer token, if not empty. It takes precedence over the
+	// basic credentials.
+	BearerToken string
+
+	// Header has extra headers to send (e.g. the logical cluster of a managed registry). It may be nil.
+	Header http.Header
+}
+
+// CredentialsProvider provides the Credentials of the requests. It's consulted for every request, so it may rotate
+// the credentials. Implementations must be safe for concurrent use.
+type CredentialsProvider interface {
+	// Credentials returns the credentials to use for a request bound to ctx.
+	Credentials(ctx context.Context) (*Credentials, error)
+}
+
+// CredentialsInvalidator is implemented by the CredentialsProviders whose credentials may be rejected before they
+// expire (e.g. revoked tokens). When the registry rejects a request with 401, InvalidateCredentials is called with
+// the rejected credentials and the request is repeated once, with the credentials provided then.
+type CredentialsInvalidator interface {
+	// InvalidateCredentials discards credentials, if they're still the current ones.
+	InvalidateCredentials(credentials *Credentials)
+}
+
+// CredentialsProviderFunc adapts a function to CredentialsProvider.
+type CredentialsProviderFunc func(ctx context.Context) (*Credentials, error)
+
+// Credentials calls f(ctx).
+func (f CredentialsProviderFunc) Credentials(ctx context.Context) (*Credentials, error) {
+	return f(ctx)
+}
+
+type staticCredentials struct {
+	credentials Credentials
+}
+
+func (c *staticCredentials) Credentials(ctx context.Context) (*Credentials, error) {
+	return &c.credentials, nil
+}
+
+// WithBasicAuth authenticates the requests with HTTP Basic auth. It overrides the user info of the endpoint URLs.
+func WithBasicAuth(username string, password string) Option {
+	return WithCredentialsProvider(&staticCredentials{Credentials{Username: username, Password: password}})
+}
+
+// WithBasicAuthUserInfo is like WithBasicAuth, taking the credentials as "username:password" (like the
+// basic.auth.user.info setting of the Confluent clients).
+func WithBasicAuthUserInfo(userInfo string) Option {
+	i := strings.Index(userInfo, ":")
+	if i < 0 {
+		return func(r *registry) error {
+			// never include userInfo in the error, it may be a password
+			return errors.New("invalid basic auth user info: expected username:password")
+		}
+	}
+	return WithBasicAuth(userInfo[:i], userInfo[i+1:])
+}
+
+// WithBearerToken authenticates the requests with a static bearer token.
+func WithBearerToken(token string) Option {
+	return WithCredentialsProvider(&staticCredentials{Credentials{BearerToken: token}})
+}
+
+// WithCredentialsProvider authenticates the requests with the credentials of provider, consulted for every request.
+// It overrides the user info of the endpoint URLs.
+func WithCredentialsProvider(provider CredentialsProvider) Option {
+	return func(r *registry) error {
+		if provider == nil {
+			return errors.New("nil credentials provider")
+		}
+		r.credentials = provider
+		return nil
+	}
+}
+
+// credentialsProvider returns the CredentialsProvider for the requests to e: the one of the registry or, if none,
+// the one of the endpoint. It may be nil.
+func (r *registry) credentialsProvider(e *endpoint) CredentialsProvider {
+	if r.credentials != nil {
+		return r.credentials
+	}
+	return e.credentials
+}
+
+// authenticate sets the credentials of req, if any, returning them.
+func (r *registry) authenticate(ctx context.Context, e *endpoint, req *http.Request) (*Credentials, error) {
+	provider := r.credentialsProvider(e)
+	if provider == nil {
+		return nil, nil
+	}
+	credentials, err := provider.Credentials(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "error getting credentials")
+	}
+	if credentials == nil {
+		return nil, nil
+	}
+	for name, values := range credentials.Header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+	switch {
+	case credentials.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+credentials.BearerToken)
+	case credentials.Username != "":
+		req.SetBasicAuth(credentials.Username, credentials.Password)
+	}
+	return credentials, nil
+}
@@ -0,0 +1,111 @@
+package avro_test
+
+import (
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/larixsource/go-schema-registry/avro"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const writerSchemaV2 = `{
+  "type": "record",
+  "name": "Frame",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "int"},
+    {"name": "data", "type": "bytes"},
+    {"name": "source", "type": "string"},
+    {"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["VIDEO", "AUDIO"]}},
+    {"name": "ratio", "type": "float"}
+  ]
+}`
+
+const readerSchemaV1 = `{
+  "type": "record",
+  "name": "Frame",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "source", "type": ["null", "string"]},
+    {"name": "kind", "type": {"type": "enum", "name": "Kind", "symbols": ["VIDEO", "AUDIO", "SUBTITLES"]}},
+    {"name": "ratio", "type": "double"},
+    {"name": "codec", "type": "string", "default": "h264"}
+  ]
+}`
+
+type readerFrame struct {
+	ID     int64   `avro:"id"`
+	Source string  `avro:"source"`
+	Kind   string  `avro:"kind"`
+	Ratio  float64 `avro:"ratio"`
+	Codec  string  `avro:"codec"`
+}
+
+func TestReaderDeserializer_Resolution(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("RegisterSubjectSchema", "frames-value", writerSchemaV2).Return(12, nil)
+	registry.On("Schema", 12).Return(writerSchemaV2, nil).Once()
+
+	message, err := avro.NewSerializer(registry).Serialize("frames-value", writerSchemaV2, map[string]interface{}{
+		"id":     42,
+		"data":   []byte("abc"),
+		"source": "camera-1",
+		"kind":   "AUDIO",
+		"ratio":  float32(1.5),
+	})
+	require.Nil(t, err)
+
+	deserializer, err := avro.NewReaderDeserializer(registry, readerSchemaV1)
+	require.Nil(t, err)
+	for i := 0; i < 3; i++ {
+		var decoded readerFrame
+		require.Nil(t, deserializer.Deserialize(message, &decoded))
+		assert.Equal(t, readerFrame{ID: 42, Source: "camera-1", Kind: "AUDIO", Ratio: 1.5, Codec: "h264"}, decoded)
+	}
+
+	var generic map[string]interface{}
+	require.Nil(t, deserializer.Deserialize(message, &generic))
+	assert.Equal(t, int64(42), generic["id"])
+	assert.Equal(t, "h264", generic["codec"])
+	assert.NotContains(t, generic, "data")
+
+	// the writer schema is resolved once
+	registry.AssertExpectations(t)
+}
+
+func TestReaderDeserializer_Incompatible(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("RegisterSubjectSchema", "frames-value", writerSchemaV2).Return(12, nil)
+	registry.On("Schema", 12).Return(writerSchemaV2, nil)
+
+	message, err := avro.NewSerializer(registry).Serialize("frames-value", writerSchemaV2, map[string]interface{}{
+		"id":     42,
+		"data":   []byte("abc"),
+		"source": "camera-1",
+		"kind":   "AUDIO",
+		"ratio":  float32(1.5),
+	})
+	require.Nil(t, err)
+
+	// a required field without default, missing in the writer schema
+	deserializer, err := avro.NewReaderDeserializer(registry, `{
+  "type": "record",
+  "name": "Frame",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "width", "type": "int"}
+  ]
+}`)
+	require.Nil(t, err)
+
+	var decoded map[string]interface{}
+	assert.Error(t, deserializer.Deserialize(message, &decoded))
+}
+
+func TestReaderDeserializer_InvalidReaderSchema(t *testing.T) {
+	_, err := avro.NewReaderDeserializer(&schemaregistry.MockRegistry{}, `{"type": "record"}`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,286 @@
+// Package avro implements Avro serializers and deserializers of Kafka messages in the Confluent wire format, with the
+// schemas managed by a schema registry.
+package avro
+
+import (
+	"sync"
+
+	hamba "github.com/hamba/avro/v2"
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+)
+
+// Serializer encodes values with Avro, in messages in the Confluent wire format. It's safe for concurrent use.
+type Serializer struct {
+	registry       schemaregistry.Registry
+	autoRegister   bool
+	useLatest      bool
+	requireSubject bool
+	nameStrategy   schemaregistry.SubjectNameStrategy
+
+	// ids caches the ids of the schemas by subject and schema (subjectSchema -> int)
+	ids sync.Map
+
+	schemas schemaCache
+}
+
+// SerializerOption configures a Serializer.
+type SerializerOption func(*Serializer)
+
+// AutoRegisterSchemas sets if the schemas are registered in the subjects (the default, like the
+// auto.register.schemas setting of the Confluent serializers). If disabled, the schemas must be already registered.
+func AutoRegisterSchemas(enabled bool) SerializerOption {
+	return func(s *Serializer) {
+		s.autoRegister = enabled
+	}
+}
+
+// UseLatestVersion makes the Serializer encode the values with the latest schema of the subject, instead of the
+// given schema (like the use.latest.version setting of the Confluent serializers). Nothing is registered. Wrap the
+// registry with a CachedRegistry to avoid asking the registry for the latest version of every message.
+func UseLatestVersion() SerializerOption {
+	return func(s *Serializer) {
+		s.useLatest = true
+	}
+}
+
+// RequireSubject makes the Serializer fail if the subject doesn't exist (with a SubjectNotFound *APIError), instead
+// of creating it when registering the first schema.
+func RequireSubject() SerializerOption {
+	return func(s *Serializer) {
+		s.requireSubject = true
+	}
+}
+
+// WithSubjectNameStrategy sets the strategy naming the subjects of the topics in SerializeKey and SerializeValue.
+// The default is schemaregistry.TopicNameStrategy.
+func WithSubjectNameStrategy(strategy schemaregistry.SubjectNameStrategy) SerializerOption {
+	return func(s *Serializer) {
+		s.nameStrategy = strategy
+	}
+}
+
+// NewSerializer returns a Serializer of the schemas of registry.
+func NewSerializer(registry schemaregistry.Registry, opts ...SerializerOption) *Serializer {
+	s := &Serializer{
+		registry:     registry,
+		autoRegister: true,
+		nameStrategy: schemaregistry.TopicNameStrategy,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type subjectSchema struct {
+	subject string
+	schema  string
+}
+
+// Serialize encodes v with schema, registering or looking up the schema in subject, and returns the message in the
+// wire format. v may be a struct (with avro field tags), a map[string]interface{} or any value supported by
+// github.com/hamba/avro. With UseLatestVersion, schema is ignored.
+func (s *Serializer) Serialize(subject string, schema string, v interface{}) ([]byte, error) {
+	id, parsed, err := s.schema(subject, schema)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := hamba.Marshal(parsed, v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error encoding Avro value of subject %s", subject)
+	}
+	message := make([]byte, 0, schemaregistry.WireHeaderSize+len(payload))
+	message = schemaregistry.AppendWireHeader(message, id)
+	return append(message, payload...), nil
+}
+
+// SerializeKey is like Serialize, for a key of topic. The subject is named by the SubjectNameStrategy, with the
+// full name of the schema (if it's a named type) as record name.
+func (s *Serializer) SerializeKey(topic string, schema string, v interface{}) ([]byte, error) {
+	return s.serializeTopic(topic, true, schema, v)
+}
+
+// SerializeValue is like Serialize, for a value of topic. The subject is named by the SubjectNameStrategy, with the
+// full name of the schema (if it's a named type) as record name.
+func (s *Serializer) SerializeValue(topic string, schema string, v interface{}) ([]byte, error) {
+	return s.serializeTopic(topic, false, schema, v)
+}
+
+func (s *Serializer) serializeTopic(topic string, isKey bool, schema string, v interface{}) ([]byte, error) {
+	var recordName string
+	// with UseLatestVersion, the schema may be empty (only needed by the strategies using the record name)
+	if schema != "" || !s.useLatest {
+		parsed, err := s.schemas.parse(schema)
+		if err != nil {
+			return nil, err
+		}
+		if named, ok := parsed.(hamba.NamedSchema); ok {
+			recordName = named.FullName()
+		}
+	}
+	subject, err := s.nameStrategy.Subject(topic, isKey, recordName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error naming subject of topic %s", topic)
+	}
+	return s.Serialize(subject, schema, v)
+}
+
+// schema returns the id and parsed schema to encode the values of subject.
+func (s *Serializer) schema(subject string, schema string) (int, hamba.Schema, error) {
+	if s.useLatest {
+		ss, err := s.registry.SubjectVersion(subject, schemaregistry.Latest)
+		if err != nil {
+			return 0, nil, err
+		}
+		parsed, err := s.schemas.parse(ss.Schema)
+		if err != nil {
+			return 0, nil, err
+		}
+		return ss.ID, parsed, nil
+	}
+
+	// the schema is parsed first, so invalid schemas are never sent to the registry
+	parsed, err := s.schemas.parse(schema)
+	if err != nil {
+		return 0, nil, err
+	}
+	key := subjectSchema{subject: subject, schema: schema}
+	if id, ok := s.ids.Load(key); ok {
+		return id.(int), parsed, nil
+	}
+
+	var id int
+	if s.autoRegister {
+		if s.requireSubject {
+			_, err = s.registry.SubjectVersions(subject)
+			if err != nil {
+				return 0, nil, err
+			}
+		}
+		id, err = s.registry.RegisterSubjectSchema(subject, schema)
+		if err != nil {
+			return 0, nil, err
+		}
+	} else {
+		ss, err := s.registry.CheckSubjectSchema(subject, schema)
+		if err != nil {
+			return 0, nil, err
+		}
+		id = ss.ID
+	}
+	s.ids.Store(key, id)
+	return id, parsed, nil
+}
+
+// Deserializer decodes Avro messages in the wire format, with the writer schemas of the registry. It's safe for
+// concurrent use.
+type Deserializer struct {
+	registry schemaregistry.Registry
+
+	// writers caches the parsed writer schemas by id (int -> hamba.Schema)
+	writers sync.Map
+
+	// reader is the schema the messages are resolved to, or nil to decode them with their writer schemas
+	reader hamba.Schema
+
+	// plans caches the writer schemas resolved to the reader schema, by writer schema id (int -> hamba.Schema)
+	plans sync.Map
+
+	schemas schemaCache
+}
+
+// NewDeserializer returns a Deserializer of the schemas of registry.
+func NewDeserializer(registry schemaregistry.Registry) *Deserializer {
+	return &Deserializer{
+		registry: registry,
+	}
+}
+
+// NewReaderDeserializer returns a Deserializer of the schemas of registry that resolves the messages to
+// readerSchema, following the Avro schema resolution rules: fields missing in the writer schema are set to their
+// defaults, fields missing in the reader schema are skipped, numeric types are promoted, and union branches and enum
+// symbols are matched by name. The resolution of every writer schema is cached.
+func NewReaderDeserializer(registry schemaregistry.Registry, readerSchema string) (*Deserializer, error) {
+	d := NewDeserializer(registry)
+	reader, err := d.schemas.parse(readerSchema)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid reader schema")
+	}
+	d.reader = reader
+	return d, nil
+}
+
+// Deserialize decodes message into v, which must be a pointer. Records are decoded into structs (with avro field
+// tags), maps (map[string]interface{}) or, with a *interface{}, generic values (records as maps).
+func (d *Deserializer) Deserialize(message []byte, v interface{}) error {
+	id, payload, err := schemaregistry.ParseWire(message)
+	if err != nil {
+		return err
+	}
+	schema, err := d.decodingSchema(id)
+	if err != nil {
+		return err
+	}
+	err = hamba.Unmarshal(schema, payload, v)
+	if err != nil {
+		return errors.Wrapf(err, "error decoding Avro message of schema %d", id)
+	}
+	return nil
+}
+
+// decodingSchema returns the schema to decode the messages of the writer schema id: the writer schema, or its
+// resolution to the reader schema.
+func (d *Deserializer) decodingSchema(id int) (hamba.Schema, error) {
+	if d.reader == nil {
+		return d.writer(id)
+	}
+	if plan, ok := d.plans.Load(id); ok {
+		return plan.(hamba.Schema), nil
+	}
+	writer, err := d.writer(id)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := hamba.NewSchemaCompatibility().Resolve(d.reader, writer)
+	if err != nil {
+		return nil, errors.Wrapf(err, "writer schema %d can't be resolved to the reader schema", id)
+	}
+	d.plans.Store(id, plan)
+	return plan, nil
+}
+
+// writer returns the parsed writer schema of id.
+func (d *Deserializer) writer(id int) (hamba.Schema, error) {
+	if writer, ok := d.writers.Load(id); ok {
+		return writer.(hamba.Schema), nil
+	}
+	schema, err := d.registry.Schema(id)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := d.schemas.parse(schema)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid writer schema %d", id)
+	}
+	d.writers.Store(id, writer)
+	return writer, nil
+}
+
+// schemaCache caches parsed schemas by their JSON (string -> hamba.Schema).
+type schemaCache struct {
+	schemas sync.Map
+}
+
+func (c *schemaCache) parse(schema string) (hamba.Schema, error) {
+	if parsed, ok := c.schemas.Load(schema); ok {
+		return parsed.(hamba.Schema), nil
+	}
+	// every schema is parsed with its own cache of named types, so different versions of a record don't clash
+	parsed, err := hamba.ParseWithCache(schema, "", &hamba.SchemaCache{})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid Avro schema")
+	}
+	c.schemas.Store(schema, parsed)
+	return parsed, nil
+}
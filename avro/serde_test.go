@@ -0,0 +1,186 @@
+package avro_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/larixsource/go-schema-registry/avro"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const frameSchema = `{
+  "type": "record",
+  "name": "Frame",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"},
+    {"name": "data", "type": "bytes"}
+  ]
+}`
+
+type frame struct {
+	ID   int64  `avro:"id"`
+	Data []byte `avro:"data"`
+}
+
+func subjectNotFound() error {
+	return &schemaregistry.APIError{
+		Code:       schemaregistry.SubjectNotFound,
+		Message:    "Subject not found",
+		StatusCode: http.StatusNotFound,
+	}
+}
+
+func TestSerializer_AutoRegister(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("RegisterSubjectSchema", "frames-value", frameSchema).Return(7, nil).Once()
+	registry.On("Schema", 7).Return(frameSchema, nil).Once()
+
+	serializer := avro.NewSerializer(registry)
+	var message []byte
+	for i := 0; i < 3; i++ {
+		var err error
+		message, err = serializer.Serialize("frames-value", frameSchema, &frame{ID: 42, Data: []byte("abc")})
+		require.Nil(t, err)
+	}
+	assert.Equal(t, []byte{0, 0, 0, 0, 7, 84, 6, 'a', 'b', 'c'}, message)
+
+	deserializer := avro.NewDeserializer(registry)
+	for i := 0; i < 3; i++ {
+		var decoded frame
+		require.Nil(t, deserializer.Deserialize(message, &decoded))
+		assert.Equal(t, frame{ID: 42, Data: []byte("abc")}, decoded)
+	}
+
+	var generic interface{}
+	require.Nil(t, deserializer.Deserialize(message, &generic))
+	assert.Equal(t, map[string]interface{}{"id": int64(42), "data": []byte("abc")}, generic)
+
+	// registered and resolved once
+	registry.AssertExpectations(t)
+}
+
+func TestSerializer_Map(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("RegisterSubjectSchema", "frames-value", frameSchema).Return(7, nil)
+
+	serializer := avro.NewSerializer(registry)
+	message, err := serializer.Serialize("frames-value", frameSchema,
+		map[string]interface{}{"id": int64(42), "data": []byte("abc")})
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 7, 84, 6, 'a', 'b', 'c'}, message)
+}
+
+func TestSerializer_NoAutoRegister(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("CheckSubjectSchema", "frames-value", frameSchema).Return(&schemaregistry.SubjectSchema{
+		Subject: "frames-value",
+		ID:      7,
+		Version: 2,
+		Schema:  frameSchema,
+	}, nil).Once()
+	registry.On("CheckSubjectSchema", "other-value", frameSchema).Return((*schemaregistry.SubjectSchema)(nil), subjectNotFound())
+
+	serializer := avro.NewSerializer(registry, avro.AutoRegisterSchemas(false))
+	for i := 0; i < 2; i++ {
+		message, err := serializer.Serialize("frames-value", frameSchema, &frame{ID: 42})
+		require.Nil(t, err)
+		assert.Equal(t, []byte{0, 0, 0, 0, 7, 84, 0}, message)
+	}
+
+	_, err := serializer.Serialize("other-value", frameSchema, &frame{ID: 42})
+	var apiErr *schemaregistry.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, schemaregistry.SubjectNotFound, apiErr.Code)
+
+	registry.AssertExpectations(t)
+	registry.AssertNotCalled(t, "RegisterSubjectSchema", mock.Anything, mock.Anything)
+}
+
+func TestSerializer_UseLatestVersion(t *testing.T) {
+	latestSchema := `{
+  "type": "record",
+  "name": "Frame",
+  "namespace": "com.example",
+  "fields": [
+    {"name": "id", "type": "long"}
+  ]
+}`
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("SubjectVersion", "frames-value", schemaregistry.Latest).Return(&schemaregistry.SubjectSchema{
+		Subject: "frames-value",
+		ID:      9,
+		Version: 3,
+		Schema:  latestSchema,
+	}, nil)
+
+	serializer := avro.NewSerializer(registry, avro.UseLatestVersion())
+	message, err := serializer.Serialize("frames-value", frameSchema, &frame{ID: 42, Data: []byte("abc")})
+	require.Nil(t, err)
+	// encoded with the latest schema, without data
+	assert.Equal(t, []byte{0, 0, 0, 0, 9, 84}, message)
+
+	registry.AssertNotCalled(t, "RegisterSubjectSchema", mock.Anything, mock.Anything)
+}
+
+func TestSerializer_RequireSubject(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("SubjectVersions", "frames-value").Return([]int{1, 2}, nil)
+	registry.On("SubjectVersions", "other-value").Return([]int(nil), subjectNotFound())
+	registry.On("RegisterSubjectSchema", "frames-value", frameSchema).Return(7, nil)
+
+	serializer := avro.NewSerializer(registry, avro.RequireSubject())
+	_, err := serializer.Serialize("frames-value", frameSchema, &frame{ID: 42})
+	require.Nil(t, err)
+
+	_, err = serializer.Serialize("other-value", frameSchema, &frame{ID: 42})
+	var apiErr *schemaregistry.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, schemaregistry.SubjectNotFound, apiErr.Code)
+	registry.AssertNotCalled(t, "RegisterSubjectSchema", "other-value", frameSchema)
+}
+
+func TestSerializer_InvalidSchema(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+
+	serializer := avro.NewSerializer(registry)
+	_, err := serializer.Serialize("frames-value", `{"type": "record"}`, &frame{ID: 42})
+	assert.Error(t, err)
+	registry.AssertNotCalled(t, "RegisterSubjectSchema", mock.Anything, mock.Anything)
+}
+
+func TestSerializer_InvalidValue(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("RegisterSubjectSchema", "frames-value", frameSchema).Return(7, nil)
+
+	serializer := avro.NewSerializer(registry)
+	_, err := serializer.Serialize("frames-value", frameSchema, "not a frame")
+	assert.Error(t, err)
+}
+
+func TestDeserializer_Errors(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("Schema", 8).Return("", &schemaregistry.APIError{
+		Code:    schemaregistry.SchemaNotFound,
+		Message: "Schema not found",
+	})
+	deserializer := avro.NewDeserializer(registry)
+
+	var decoded frame
+	err := deserializer.Deserialize([]byte{1, 0, 0, 0, 7, 84, 0}, &decoded)
+	var magicErr schemaregistry.UnknownMagicByteError
+	require.True(t, errors.As(err, &magicErr))
+	assert.Equal(t, byte(1), magicErr.MagicByte)
+
+	err = deserializer.Deserialize([]byte{0, 0}, &decoded)
+	assert.Equal(t, schemaregistry.ErrTruncatedMessage, err)
+
+	err = deserializer.Deserialize([]byte{0, 0, 0, 0, 8, 84, 0}, &decoded)
+	var apiErr *schemaregistry.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, schemaregistry.SchemaNotFound, apiErr.Code)
+}
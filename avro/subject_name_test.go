@@ -0,0 +1,58 @@
+package avro_test
+
+import (
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/larixsource/go-schema-registry/avro"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSerializer_TopicNameStrategy(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("RegisterSubjectSchema", "frames-key", `"long"`).Return(3, nil).Once()
+	registry.On("RegisterSubjectSchema", "frames-value", frameSchema).Return(7, nil).Once()
+
+	serializer := avro.NewSerializer(registry)
+	key, err := serializer.SerializeKey("frames", `"long"`, int64(42))
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 3, 84}, key)
+
+	value, err := serializer.SerializeValue("frames", frameSchema, &frame{ID: 42})
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 7, 84, 0}, value)
+
+	registry.AssertExpectations(t)
+}
+
+func TestSerializer_RecordNameStrategy(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("RegisterSubjectSchema", "com.example.Frame", frameSchema).Return(7, nil).Once()
+
+	serializer := avro.NewSerializer(registry, avro.WithSubjectNameStrategy(schemaregistry.RecordNameStrategy))
+	_, err := serializer.SerializeValue("frames", frameSchema, &frame{ID: 42})
+	require.Nil(t, err)
+
+	// primitive schemas have no record name
+	_, err = serializer.SerializeKey("frames", `"long"`, int64(42))
+	assert.Equal(t, schemaregistry.ErrNoRecordName, errors.Cause(err))
+
+	registry.AssertExpectations(t)
+}
+
+func TestSerializer_CustomNameStrategy(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("RegisterSubjectSchema", "staging.frames.com.example.Frame", frameSchema).Return(7, nil).Once()
+
+	strategy := schemaregistry.SubjectNameStrategyFunc(func(topic string, isKey bool, recordName string) (string,
+		error) {
+		return "staging." + topic + "." + recordName, nil
+	})
+	serializer := avro.NewSerializer(registry, avro.WithSubjectNameStrategy(strategy))
+	_, err := serializer.SerializeValue("frames", frameSchema, &frame{ID: 42})
+	require.Nil(t, err)
+
+	registry.AssertExpectations(t)
+}
@@ -0,0 +1,371 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultCacheTTL is the default time to live of the cached latest versions and configs of CachedRegistry.
+	DefaultCacheTTL = 5 * time.Minute
+
+	// DefaultCacheSize is the default max number of cached subject versions and configs of CachedRegistry.
+	DefaultCacheSize = 1000
+)
+
+// CacheOption configures a CachedRegistry.
+type CacheOption func(*CachedRegistry)
+
+// WithCacheTTL sets the time to live of the cached latest versions and configs. Immutable data (schemas by id,
+// specific subject versions and the ids of the registered schemas) never expires.
+//
+// A zero TTL (or negative, which is clamped to zero) disables the expiration: the latest versions and configs are
+// cached until evicted, or until updated through the CachedRegistry, so changes done by other clients are never seen.
+func WithCacheTTL(ttl time.Duration) CacheOption {
+	return func(c *CachedRegistry) {
+		if ttl < 0 {
+			ttl = 0
+		}
+		c.ttl = ttl
+	}
+}
+
+// WithCacheSize sets the max number of cached subject versions and configs. When full, the least recently used entry
+// is evicted. A zero size (or negative, which is clamped to zero) disables the caching of subject versions and
+// configs.
+func WithCacheSize(size int) CacheOption {
+	return func(c *CachedRegistry) {
+		if size < 0 {
+			size = 0
+		}
+		c.size = size
+	}
+}
+
+// CachedRegistry is a Registry that caches the results of another Registry:
+//
+//   - schemas by id are cached forever (ids are immutable).
+//   - the results of CheckSubjectSchema and RegisterSubjectSchema are cached forever, keyed by subject and
+//     canonicalized schema and schema options (so schemas differing only in whitespace or in the order of JSON keys
+//     share an entry).
+//   - subject versions and configs are cached in a bounded LRU. Latest versions and configs expire after a TTL,
+//     while specific versions don't expire.
+//
+// Updates done through the CachedRegistry (registering a schema, setting a config, etc.) invalidate the affected
+// entries. Concurrent identical lookups that miss the cache share a single call to the wrapped Registry. A
+// CachedRegistry is safe for concurrent use.
+type CachedRegistry struct {
+	registry Registry
+	ttl      time.Duration
+	size     int
+
+	// schemas caches the schema strings by id (int -> string)
+	schemas sync.Map
+
+	// schemasByID caches the results of SchemaByID (int -> *SubjectSchema)
+	schemasByID sync.Map
+
+	// subjectSchemas caches the SubjectSchema of the checked schemas by subject and canonical schema
+	// (subjectSchemaKey -> *SubjectSchema).
+	subjectSchemas sync.Map
+
+	// ids caches the ids of the registered schemas by subject and canonical schema (subjectSchemaKey -> int).
+	ids sync.Map
+
+	// lru caches subject versions and configs
+	lru *lru
+
+	// flights coalesces the concurrent identical lookups that miss the cache
+	flights flightGroup
+}
+
+// NewCachedRegistry returns a CachedRegistry wrapping registry.
+func NewCachedRegistry(registry Registry, opts ...CacheOption) *CachedRegistry {
+	c := &CachedRegistry{
+		registry: registry,
+		ttl:      DefaultCacheTTL,
+		size:     DefaultCacheSize,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.lru = newLRU(c.size)
+	return c
+}
+
+type subjectSchemaKey struct {
+	subject string
+	schema  string
+
+	// options is the JSON of the schema options (type, etc.)
+	options string
+}
+
+func newSubjectSchemaKey(subject string, schema string, opts []SchemaOption) subjectSchemaKey {
+	options, _ := json.Marshal(newSchemaJSON("", opts))
+	return subjectSchemaKey{subject: subject, schema: canonicalSchema(schema), options: string(options)}
+}
+
+// canonicalSchema returns the canonical form of a JSON schema: compact and with sorted object keys. Schemas that
+// aren't valid JSON are returned unchanged.
+func canonicalSchema(schema string) string {
+	// numbers are kept as literals, as float64 would merge different large numbers (like long defaults)
+	decoder := json.NewDecoder(strings.NewReader(schema))
+	decoder.UseNumber()
+	var v interface{}
+	err := decoder.Decode(&v)
+	if err != nil || decoder.Decode(new(interface{})) != io.EOF {
+		return schema
+	}
+	canonical, err := json.Marshal(v)
+	if err != nil {
+		return schema
+	}
+	return string(canonical)
+}
+
+func subjectVersionKey(subject string, version int) string {
+	return subjectVersionsPrefix(subject) + versionSegment(version)
+}
+
+// subjectVersionsPrefix is the prefix of the keys of the versions of subject (and of the subjects named like
+// "<subject>:...").
+func subjectVersionsPrefix(subject string) string {
+	return "version:" + subject + ":"
+}
+
+func subjectConfigKey(subject string) string {
+	return "config:" + subject
+}
+
+// globalConfigKey can't clash with subjectConfigKey, as subject config keys always have a ':' after "config".
+const globalConfigKey = "config"
+
+func (c *CachedRegistry) Schema(id int) (string, error) {
+	if schema, ok := c.schemas.Load(id); ok {
+		return schema.(string), nil
+	}
+	schema, err := c.flights.do(context.Background(), "schema:"+strconv.Itoa(id), func(context.Context) (interface{},
+		error) {
+		schema, err := c.registry.Schema(id)
+		if err != nil {
+			return nil, err
+		}
+		c.schemas.Store(id, schema)
+		return schema, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return schema.(string), nil
+}
+
+func (c *CachedRegistry) SchemaByID(id int) (*SubjectSchema, error) {
+	if ss, ok := c.schemasByID.Load(id); ok {
+		return copySubjectSchema(ss.(*SubjectSchema)), nil
+	}
+	ss, err := c.flights.do(context.Background(), "schemaByID:"+strconv.Itoa(id), func(context.Context) (interface{},
+		error) {
+		ss, err := c.registry.SchemaByID(id)
+		if err != nil {
+			return nil, err
+		}
+		c.schemasByID.Store(id, copySubjectSchema(ss))
+		return ss, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copySubjectSchema(ss.(*SubjectSchema)), nil
+}
+
+func (c *CachedRegistry) SchemaTypes() ([]SchemaType, error) {
+	return c.registry.SchemaTypes()
+}
+
+func (c *CachedRegistry) ReferencedBy(subject string, version int) ([]int, error) {
+	return c.registry.ReferencedBy(subject, version)
+}
+
+func (c *CachedRegistry) Subjects(opts ...ListOption) ([]string, error) {
+	return c.registry.Subjects(opts...)
+}
+
+func (c *CachedRegistry) SubjectVersions(subject string, opts ...ListOption) ([]int, error) {
+	return c.registry.SubjectVersions(subject, opts...)
+}
+
+func (c *CachedRegistry) SubjectVersion(subject string, version int) (*SubjectSchema, error) {
+	key := subjectVersionKey(subject, version)
+	if ss, ok := c.lru.get(key); ok {
+		return copySubjectSchema(ss.(*SubjectSchema)), nil
+	}
+	ss, err := c.flights.do(context.Background(), key, func(context.Context) (interface{}, error) {
+		ss, err := c.registry.SubjectVersion(subject, version)
+		if err != nil {
+			return nil, err
+		}
+		ttl := time.Duration(0)
+		if version == Latest {
+			ttl = c.ttl
+		}
+		c.lru.add(key, copySubjectSchema(ss), ttl)
+		c.schemas.Store(ss.ID, ss.Schema)
+		return ss, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copySubjectSchema(ss.(*SubjectSchema)), nil
+}
+
+func (c *CachedRegistry) DeleteSubject(subject string, opts ...DeleteOption) ([]int, error) {
+	defer c.forgetSubject(subject)
+	return c.registry.DeleteSubject(subject, opts...)
+}
+
+func (c *CachedRegistry) DeleteSubjectVersion(subject string, version int, opts ...DeleteOption) (int, error) {
+	// the ids of the registered schemas aren't cached by version, so all the subject is forgotten
+	defer c.forgetSubject(subject)
+	return c.registry.DeleteSubjectVersion(subject, version, opts...)
+}
+
+// forgetSubject invalidates the cached versions of subject, and the cached results of CheckSubjectSchema and
+// RegisterSubjectSchema in subject. Schemas by id are kept, as soft-deleted schemas are still got by id.
+func (c *CachedRegistry) forgetSubject(subject string) {
+	c.lru.removePrefix(subjectVersionsPrefix(subject))
+	forget := func(key, _ interface{}) bool {
+		if key.(subjectSchemaKey).subject == subject {
+			c.subjectSchemas.Delete(key)
+			c.ids.Delete(key)
+		}
+		return true
+	}
+	c.subjectSchemas.Range(forget)
+	c.ids.Range(forget)
+}
+
+func (c *CachedRegistry) RegisterSubjectSchema(subject string, schema string, opts ...SchemaOption) (int, error) {
+	key := newSubjectSchemaKey(subject, schema, opts)
+	if id, ok := c.ids.Load(key); ok {
+		return id.(int), nil
+	}
+	if ss, ok := c.subjectSchemas.Load(key); ok {
+		return ss.(*SubjectSchema).ID, nil
+	}
+	id, err := c.registry.RegisterSubjectSchema(subject, schema, opts...)
+	if err != nil {
+		return 0, err
+	}
+	c.ids.Store(key, id)
+	c.schemas.Store(id, schema)
+	// the registered schema may be a new version of the subject
+	c.lru.remove(subjectVersionKey(subject, Latest))
+	return id, nil
+}
+
+func (c *CachedRegistry) CheckSubjectSchema(subject string, schema string, opts ...SchemaOption) (*SubjectSchema,
+	error) {
+	key := newSubjectSchemaKey(subject, schema, opts)
+	if ss, ok := c.subjectSchemas.Load(key); ok {
+		return copySubjectSchema(ss.(*SubjectSchema)), nil
+	}
+	flight := "check:" + key.subject + "\x00" + key.schema + "\x00" + key.options
+	ss, err := c.flights.do(context.Background(), flight, func(context.Context) (interface{}, error) {
+		ss, err := c.registry.CheckSubjectSchema(subject, schema, opts...)
+		if err != nil {
+			return nil, err
+		}
+		c.subjectSchemas.Store(key, copySubjectSchema(ss))
+		c.ids.Store(key, ss.ID)
+		c.schemas.Store(ss.ID, ss.Schema)
+		return ss, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copySubjectSchema(ss.(*SubjectSchema)), nil
+}
+
+func (c *CachedRegistry) TestCompatibility(subject string, version int, schema string, opts ...SchemaOption) (bool,
+	error) {
+	return c.registry.TestCompatibility(subject, version, schema, opts...)
+}
+
+func (c *CachedRegistry) TestCompatibilityAll(subject string, schema string,
+	opts ...SchemaOption) (*CompatibilityResult, error) {
+	return c.registry.TestCompatibilityAll(subject, schema, opts...)
+}
+
+func (c *CachedRegistry) SetConfig(config *Config) (*Config, error) {
+	defer c.lru.remove(globalConfigKey)
+	return c.registry.SetConfig(config)
+}
+
+func (c *CachedRegistry) Config() (*Config, error) {
+	return c.config(globalConfigKey, c.registry.Config)
+}
+
+func (c *CachedRegistry) SetSubjectConfig(subject string, config *Config) (*Config, error) {
+	defer c.lru.remove(subjectConfigKey(subject))
+	return c.registry.SetSubjectConfig(subject, config)
+}
+
+func (c *CachedRegistry) SubjectConfig(subject string) (*Config, error) {
+	return c.config(subjectConfigKey(subject), func() (*Config, error) {
+		return c.registry.SubjectConfig(subject)
+	})
+}
+
+func (c *CachedRegistry) DeleteSubjectConfig(subject string) (*Config, error) {
+	defer c.lru.remove(subjectConfigKey(subject))
+	return c.registry.DeleteSubjectConfig(subject)
+}
+
+func (c *CachedRegistry) SetMode(mode Mode, opts ...ModeOption) (Mode, error) {
+	return c.registry.SetMode(mode, opts...)
+}
+
+func (c *CachedRegistry) Mode() (Mode, error) {
+	return c.registry.Mode()
+}
+
+func (c *CachedRegistry) SetSubjectMode(subject string, mode Mode, opts ...ModeOption) (Mode, error) {
+	return c.registry.SetSubjectMode(subject, mode, opts...)
+}
+
+func (c *CachedRegistry) SubjectMode(subject string) (Mode, error) {
+	return c.registry.SubjectMode(subject)
+}
+
+func (c *CachedRegistry) DeleteSubjectMode(subject string) (Mode, error) {
+	return c.registry.DeleteSubjectMode(subject)
+}
+
+// config returns the config cached under key, calling lookup on misses.
+func (c *CachedRegistry) config(key string, lookup func() (*Config, error)) (*Config, error) {
+	if config, ok := c.lru.get(key); ok {
+		copied := *config.(*Config)
+		return &copied, nil
+	}
+	config, err := lookup()
+	if err != nil {
+		return nil, err
+	}
+	cached := *config
+	c.lru.add(key, &cached, c.ttl)
+	return config, nil
+}
+
+func copySubjectSchema(ss *SubjectSchema) *SubjectSchema {
+	copied := *ss
+	if ss.References != nil {
+		copied.References = append([]SchemaReference(nil), ss.References...)
+	}
+	return &copied
+}
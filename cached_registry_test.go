@@ -0,0 +1,284 @@
+package schemaregistry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCachedRegistry_Schema(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("Schema", 1).Return(testSchema, nil).Once()
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	for i := 0; i < 3; i++ {
+		schema, err := registry.Schema(1)
+		require.Nil(t, err)
+		assert.Equal(t, testSchema, schema)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestCachedRegistry_SchemaByID(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("SchemaByID", 2).Return(&schemaregistry.SubjectSchema{
+		ID:         2,
+		Schema:     testProtoSchema,
+		SchemaType: schemaregistry.Protobuf,
+	}, nil).Once()
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	for i := 0; i < 3; i++ {
+		ss, err := registry.SchemaByID(2)
+		require.Nil(t, err)
+		assert.Equal(t, schemaregistry.Protobuf, ss.SchemaType)
+		assert.Equal(t, testProtoSchema, ss.Schema)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestCachedRegistry_SchemaOptions(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("RegisterSubjectSchema", "frames-value", `"string"`).Return(1, nil).Once()
+	mock.On("RegisterSubjectSchema", "frames-value", `"string"`, testifymock.Anything).Return(2, nil).Once()
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	// the same schema string, as Avro and as JSON Schema, are different schemas
+	for i := 0; i < 2; i++ {
+		id, err := registry.RegisterSubjectSchema("frames-value", `"string"`)
+		require.Nil(t, err)
+		assert.Equal(t, 1, id)
+
+		id, err = registry.RegisterSubjectSchema("frames-value", `"string"`,
+			schemaregistry.WithSchemaType(schemaregistry.JSON))
+		require.Nil(t, err)
+		assert.Equal(t, 2, id)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestCachedRegistry_CheckSubjectSchemaCanonical(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("CheckSubjectSchema", "frames-value", testSchema).Return(&schemaregistry.SubjectSchema{
+		Subject: "frames-value",
+		ID:      1,
+		Version: 2,
+		Schema:  testSchema,
+	}, nil).Once()
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	ss, err := registry.CheckSubjectSchema("frames-value", testSchema)
+	require.Nil(t, err)
+	assert.Equal(t, 1, ss.ID)
+
+	// same schema, different formatting and key order
+	compact := `{"fields":[{"type":"bytes","name":"data"}],"name":"Frame","type":"record"}`
+	ss, err = registry.CheckSubjectSchema("frames-value", compact)
+	require.Nil(t, err)
+	assert.Equal(t, 1, ss.ID)
+	assert.Equal(t, 2, ss.Version)
+
+	// a checked schema is already registered
+	id, err := registry.RegisterSubjectSchema("frames-value", compact)
+	require.Nil(t, err)
+	assert.Equal(t, 1, id)
+
+	// the id lookup is served from cache too
+	schema, err := registry.Schema(1)
+	require.Nil(t, err)
+	assert.Equal(t, testSchema, schema)
+	mock.AssertExpectations(t)
+}
+
+func TestCachedRegistry_CanonicalLargeNumbers(t *testing.T) {
+	t.Parallel()
+	// the defaults are the same float64, but different longs
+	schema1 := `{"type": "record", "name": "Frame",
+  "fields": [{"name": "id", "type": "long", "default": 9007199254740993}]}`
+	schema2 := `{"type": "record", "name": "Frame",
+  "fields": [{"name": "id", "type": "long", "default": 9007199254740992}]}`
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("RegisterSubjectSchema", "frames-value", schema1).Return(1, nil).Once()
+	mock.On("RegisterSubjectSchema", "frames-value", schema2).Return(2, nil).Once()
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	id, err := registry.RegisterSubjectSchema("frames-value", schema1)
+	require.Nil(t, err)
+	assert.Equal(t, 1, id)
+	id, err = registry.RegisterSubjectSchema("frames-value", schema2)
+	require.Nil(t, err)
+	assert.Equal(t, 2, id)
+	mock.AssertExpectations(t)
+}
+
+func TestCachedRegistry_RegisterSubjectSchema(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("RegisterSubjectSchema", "frames-value", testSchema).Return(5, nil).Once()
+	mock.On("RegisterSubjectSchema", "frames-key", testSchema).Return(5, nil).Once()
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	for i := 0; i < 3; i++ {
+		id, err := registry.RegisterSubjectSchema("frames-value", testSchema)
+		require.Nil(t, err)
+		assert.Equal(t, 5, id)
+	}
+	id, err := registry.RegisterSubjectSchema("frames-key", testSchema)
+	require.Nil(t, err)
+	assert.Equal(t, 5, id)
+	mock.AssertExpectations(t)
+}
+
+func TestCachedRegistry_ErrorsNotCached(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	apiErr := &schemaregistry.APIError{Code: schemaregistry.SchemaNotFound, Message: "Schema not found"}
+	mock.On("Schema", 1).Return("", apiErr).Twice()
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	for i := 0; i < 2; i++ {
+		_, err := registry.Schema(1)
+		assert.Equal(t, apiErr, err)
+	}
+	mock.AssertExpectations(t)
+}
+
+func TestCachedRegistry_LatestVersionTTL(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("SubjectVersion", "frames-value", schemaregistry.Latest).Return(&schemaregistry.SubjectSchema{
+		Subject: "frames-value",
+		ID:      1,
+		Version: 2,
+		Schema:  testSchema,
+	}, nil).Twice()
+	registry := schemaregistry.NewCachedRegistry(mock, schemaregistry.WithCacheTTL(50*time.Millisecond))
+
+	for i := 0; i < 3; i++ {
+		ss, err := registry.SubjectVersion("frames-value", schemaregistry.Latest)
+		require.Nil(t, err)
+		assert.Equal(t, 2, ss.Version)
+	}
+	mock.AssertNumberOfCalls(t, "SubjectVersion", 1)
+
+	time.Sleep(100 * time.Millisecond)
+	_, err := registry.SubjectVersion("frames-value", schemaregistry.Latest)
+	require.Nil(t, err)
+	mock.AssertExpectations(t)
+}
+
+func TestCachedRegistry_LRUEviction(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	for _, version := range []int{1, 2} {
+		mock.On("SubjectVersion", "frames-value", version).Return(&schemaregistry.SubjectSchema{
+			Subject: "frames-value",
+			ID:      version,
+			Version: version,
+			Schema:  testSchema,
+		}, nil)
+	}
+	registry := schemaregistry.NewCachedRegistry(mock, schemaregistry.WithCacheSize(1))
+
+	_, err := registry.SubjectVersion("frames-value", 1)
+	require.Nil(t, err)
+	_, err = registry.SubjectVersion("frames-value", 1)
+	require.Nil(t, err)
+	mock.AssertNumberOfCalls(t, "SubjectVersion", 1)
+
+	// version 2 evicts version 1
+	_, err = registry.SubjectVersion("frames-value", 2)
+	require.Nil(t, err)
+	_, err = registry.SubjectVersion("frames-value", 1)
+	require.Nil(t, err)
+	mock.AssertNumberOfCalls(t, "SubjectVersion", 3)
+}
+
+func TestCachedRegistry_NoLRU(t *testing.T) {
+	t.Parallel()
+	for _, size := range []int{0, -1} {
+		mock := &schemaregistry.MockRegistry{}
+		mock.On("SubjectVersion", "frames-value", 1).Return(&schemaregistry.SubjectSchema{
+			Subject: "frames-value",
+			ID:      1,
+			Version: 1,
+			Schema:  testSchema,
+		}, nil)
+		registry := schemaregistry.NewCachedRegistry(mock, schemaregistry.WithCacheSize(size))
+
+		for i := 0; i < 2; i++ {
+			ss, err := registry.SubjectVersion("frames-value", 1)
+			require.Nil(t, err)
+			assert.Equal(t, 1, ss.ID)
+		}
+		// nothing cached
+		mock.AssertNumberOfCalls(t, "SubjectVersion", 2)
+	}
+}
+
+func TestCachedRegistry_ConfigInvalidation(t *testing.T) {
+	t.Parallel()
+	full := &schemaregistry.Config{Compatibility: schemaregistry.CompatibilityLevel(schemaregistry.Full)}
+	none := &schemaregistry.Config{Compatibility: schemaregistry.CompatibilityLevel(schemaregistry.None)}
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("SubjectConfig", "frames-value").Return(full, nil).Once()
+	mock.On("SetSubjectConfig", "frames-value", none).Return(none, nil).Once()
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	for i := 0; i < 2; i++ {
+		config, err := registry.SubjectConfig("frames-value")
+		require.Nil(t, err)
+		assert.Equal(t, schemaregistry.Full, *config.Compatibility)
+	}
+	mock.AssertNumberOfCalls(t, "SubjectConfig", 1)
+
+	_, err := registry.SetSubjectConfig("frames-value", none)
+	require.Nil(t, err)
+
+	mock.On("SubjectConfig", "frames-value").Return(none, nil).Once()
+	config, err := registry.SubjectConfig("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.None, *config.Compatibility)
+	mock.AssertExpectations(t)
+}
+
+func TestCachedRegistry_DeleteInvalidation(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("SubjectVersion", "frames-value", 2).Return(&schemaregistry.SubjectSchema{
+		Subject: "frames-value", Version: 2, ID: 7, Schema: "schema",
+	}, nil).Once()
+	mock.On("RegisterSubjectSchema", "frames-value", "schema").Return(7, nil).Once()
+	mock.On("DeleteSubjectVersion", "frames-value", 2).Return(2, nil)
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	for i := 0; i < 2; i++ {
+		_, err := registry.SubjectVersion("frames-value", 2)
+		require.Nil(t, err)
+		_, err = registry.RegisterSubjectSchema("frames-value", "schema")
+		require.Nil(t, err)
+	}
+	version, err := registry.DeleteSubjectVersion("frames-value", 2)
+	require.Nil(t, err)
+	assert.Equal(t, 2, version)
+
+	mock.On("SubjectVersion", "frames-value", 2).Return((*schemaregistry.SubjectSchema)(nil), &schemaregistry.APIError{
+		Code:    schemaregistry.VersionNotFound,
+		Message: "Version not found",
+	}).Once()
+	mock.On("RegisterSubjectSchema", "frames-value", "schema").Return(8, nil).Once()
+	_, err = registry.SubjectVersion("frames-value", 2)
+	assert.Error(t, err)
+	id, err := registry.RegisterSubjectSchema("frames-value", "schema")
+	require.Nil(t, err)
+	assert.Equal(t, 8, id)
+	mock.AssertExpectations(t)
+}
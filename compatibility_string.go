@@ -4,9 +4,9 @@ package schemaregistry
 
 import "fmt"
 
-const _Compatibility_name = "NoneFullForwardBackward"
+const _Compatibility_name = "NoneFullForwardBackwardBackwardTransitiveForwardTransitiveFullTransitive"
 
-var _Compatibility_index = [...]uint8{0, 4, 8, 15, 23}
+var _Compatibility_index = [...]uint8{0, 4, 8, 15, 23, 41, 58, 72}
 
 func (i Compatibility) String() string {
 	if i < 0 || i >= Compatibility(len(_Compatibility_index)-1) {
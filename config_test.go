@@ -0,0 +1,189 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompatibility_Text(t *testing.T) {
+	t.Parallel()
+	levels := map[schemaregistry.Compatibility]string{
+		schemaregistry.None:               "NONE",
+		schemaregistry.Full:               "FULL",
+		schemaregistry.Forward:            "FORWARD",
+		schemaregistry.Backward:           "BACKWARD",
+		schemaregistry.BackwardTransitive: "BACKWARD_TRANSITIVE",
+		schemaregistry.ForwardTransitive:  "FORWARD_TRANSITIVE",
+		schemaregistry.FullTransitive:     "FULL_TRANSITIVE",
+	}
+	for c, name := range levels {
+		text, err := c.MarshalText()
+		require.Nil(t, err)
+		assert.Equal(t, name, string(text))
+
+		var decoded schemaregistry.Compatibility
+		require.Nil(t, decoded.UnmarshalText([]byte(name)))
+		assert.Equal(t, c, decoded)
+	}
+
+	var c schemaregistry.Compatibility
+	assert.Error(t, c.UnmarshalText([]byte("SIDEWAYS")))
+	_, err := schemaregistry.Compatibility(42).MarshalText()
+	assert.Error(t, err)
+}
+
+func TestRegistry_ConfigOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/config", r.URL.String())
+
+		w.Write([]byte(`{"compatibilityLevel":"FULL_TRANSITIVE","normalize":true}`))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	config, err := registry.Config()
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.FullTransitive, *config.Compatibility)
+	require.NotNil(t, config.Normalize)
+	assert.True(t, *config.Normalize)
+}
+
+func TestRegistry_SetConfigOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/config", r.URL.String())
+		assert.Equal(t, "application/vnd.schemaregistry.v1+json", r.Header.Get("Content-Type"))
+
+		var msg map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		require.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"compatibility": "BACKWARD_TRANSITIVE"}, msg)
+
+		w.Write([]byte(`{"compatibility":"BACKWARD_TRANSITIVE"}`))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	config, err := registry.SetConfig(&schemaregistry.Config{
+		Compatibility: schemaregistry.CompatibilityLevel(schemaregistry.BackwardTransitive),
+	})
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.BackwardTransitive, *config.Compatibility)
+}
+
+func TestRegistry_SetConfigPartial(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		require.Nil(t, err)
+		// the compatibility level is left untouched
+		assert.Equal(t, map[string]interface{}{"normalize": true}, msg)
+
+		w.Write([]byte(`{"normalize":true}`))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	normalize := true
+	config, err := registry.SetConfig(&schemaregistry.Config{Normalize: &normalize})
+	require.Nil(t, err)
+	assert.Nil(t, config.Compatibility)
+}
+
+func TestRegistry_SetSubjectConfigOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PUT", r.Method)
+		assert.Equal(t, "/config/frames-value", r.URL.String())
+
+		var msg map[string]interface{}
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		require.Nil(t, err)
+		assert.Equal(t, "NONE", msg["compatibility"])
+		assert.Equal(t, "frames", msg["compatibilityGroup"])
+		assert.Equal(t, map[string]interface{}{"properties": map[string]interface{}{"owner": "team-a"}},
+			msg["defaultMetadata"])
+		assert.Equal(t, map[string]interface{}{
+			"domainRules": []interface{}{map[string]interface{}{"name": "checkLen", "kind": "CONDITION"}},
+		}, msg["overrideRuleSet"])
+
+		json.NewEncoder(w).Encode(msg)
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	config, err := registry.SetSubjectConfig("frames-value", &schemaregistry.Config{
+		Compatibility:      schemaregistry.CompatibilityLevel(schemaregistry.None),
+		CompatibilityGroup: "frames",
+		DefaultMetadata: &schemaregistry.Metadata{
+			Properties: map[string]string{"owner": "team-a"},
+		},
+		OverrideRuleSet: &schemaregistry.RuleSet{
+			DomainRules: []schemaregistry.Rule{{Name: "checkLen", Kind: "CONDITION"}},
+		},
+	})
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.None, *config.Compatibility)
+	assert.Equal(t, "frames", config.CompatibilityGroup)
+	assert.Equal(t, "team-a", config.DefaultMetadata.Properties["owner"])
+	assert.Equal(t, "checkLen", config.OverrideRuleSet.DomainRules[0].Name)
+}
+
+func TestRegistry_SubjectConfigErrNotConfigured(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/config/frames-value", r.URL.String())
+
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.SubjectLevelCompatibilityNotConfigured,
+			Message: "Subject 'frames-value' does not have subject-level compatibility configured",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.SubjectConfig("frames-value")
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.Equal(t, schemaregistry.SubjectLevelCompatibilityNotConfigured, apiErr.Code)
+}
+
+func TestRegistry_DeleteSubjectConfigOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		assert.Equal(t, "/config/frames-value", r.URL.String())
+
+		w.Write([]byte(`{"compatibilityLevel":"FORWARD"}`))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	config, err := registry.DeleteSubjectConfig("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Forward, *config.Compatibility)
+}
@@ -0,0 +1,96 @@
+package schemaregistry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type countingTransport struct {
+	calls int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.calls++
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRegistry_CheckSubjectSchemaContextDeadline(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	registry, err := schemaregistry.NewContextRegistry(ts.URL)
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = registry.CheckSubjectSchemaContext(ctx, "frames-value", testSchema)
+	if assert.Error(t, err) {
+		assert.Equal(t, context.DeadlineExceeded, ctx.Err())
+	}
+}
+
+func TestRegistry_WithTimeout(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithTimeout(50*time.Millisecond))
+	require.Nil(t, err)
+
+	start := time.Now()
+	_, err = registry.Subjects()
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestRegistry_WithHTTPClientAndUserAgent(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "frames-service/1.0", r.Header.Get("User-Agent"))
+		json.NewEncoder(w).Encode([]string{"frames-value"})
+	}))
+	defer ts.Close()
+
+	transport := &countingTransport{}
+	registry, err := schemaregistry.New(ts.URL,
+		schemaregistry.WithHTTPClient(&http.Client{Transport: transport}),
+		schemaregistry.WithUserAgent("frames-service/1.0"))
+	require.Nil(t, err)
+
+	subjects, err := registry.Subjects()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"frames-value"}, subjects)
+	assert.Equal(t, 1, transport.calls)
+}
+
+func TestNewInvalidOptions(t *testing.T) {
+	t.Parallel()
+	_, err := schemaregistry.New("http://localhost:8081", schemaregistry.WithHTTPClient(nil))
+	assert.Error(t, err)
+
+	_, err = schemaregistry.New("http://localhost:8081", schemaregistry.WithTimeout(-time.Second))
+	assert.Error(t, err)
+}
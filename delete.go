@@ -0,0 +1,220 @@
+package schemaregistry
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+)
+
+// DeleteOption configures the operations DeleteSubject and DeleteSubjectVersion of Registry.
+type DeleteOption func(*deleteOptions)
+
+type deleteOptions struct {
+	permanent bool
+}
+
+func (o *deleteOptions) query() url.Values {
+	q := url.Values{}
+	if o.permanent {
+		q.Set("permanent", "true")
+	}
+	return q
+}
+
+func newDeleteOptions(opts []DeleteOption) *deleteOptions {
+	o := &deleteOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Permanent makes the delete permanent (hard): the schemas are removed from the registry. Only soft-deleted subjects
+// and versions can be permanently deleted.
+func Permanent() DeleteOption {
+	return func(o *deleteOptions) {
+		o.permanent = true
+	}
+}
+
+// DeleteError is the error of DeleteSubject and DeleteSubjectVersion when the registry refuses the delete. It wraps
+// the *APIError, with one of these codes:
+//
+//   - SubjectSoftDeleted or VersionSoftDeleted: the subject or version is already soft-deleted, and can only be
+//     deleted with Permanent().
+//   - SubjectNotSoftDeleted or VersionNotSoftDeleted: the subject or version must be soft-deleted before being
+//     permanently deleted.
+//   - ReferenceExists: other schemas reference the version (or a version of the subject).
+type DeleteError struct {
+	*APIError
+
+	// Subject is the subject being deleted.
+	Subject string
+
+	// Version is the version being deleted (Latest for the last version). It's not set if AllVersions is.
+	Version int
+
+	// AllVersions reports whether the whole subject was being deleted (with DeleteSubject).
+	AllVersions bool
+
+	// Permanent reports whether the delete was permanent.
+	Permanent bool
+}
+
+func (e *DeleteError) Error() string {
+	deleting := "deleting"
+	if e.Permanent {
+		deleting = "permanently deleting"
+	}
+	if e.AllVersions {
+		return fmt.Sprintf("error %s subject %s: %s", deleting, e.Subject, e.APIError.Error())
+	}
+	return fmt.Sprintf("error %s subject %s version %s: %s", deleting, e.Subject, versionSegment(e.Version),
+		e.APIError.Error())
+}
+
+// Unwrap returns the *APIError.
+func (e *DeleteError) Unwrap() error {
+	return e.APIError
+}
+
+// deleteError returns err as a *DeleteError if it's an *APIError with a delete error code, of a delete of version of
+// subject (or of all its versions).
+func deleteError(err error, subject string, version int, allVersions bool, permanent bool) error {
+	apiErr, ok := err.(*APIError)
+	if !ok {
+		return err
+	}
+	switch apiErr.Code {
+	case SubjectSoftDeleted, SubjectNotSoftDeleted, VersionSoftDeleted, VersionNotSoftDeleted, ReferenceExists:
+		return &DeleteError{
+			APIError:    apiErr,
+			Subject:     subject,
+			Version:     version,
+			AllVersions: allVersions,
+			Permanent:   permanent,
+		}
+	}
+	return err
+}
+
+// DeleteReport reports what a delete would remove, without deleting anything.
+type DeleteReport struct {
+	// Subject is the subject of the delete.
+	Subject string
+
+	// Versions are the versions that would be deleted. A soft delete removes the versions not deleted yet, and a
+	// permanent delete the soft-deleted ones too.
+	Versions []int
+
+	// ReferencedBy are the ids of the schemas referencing each of the Versions, if any. A delete of referenced
+	// versions fails with a *DeleteError with code ReferenceExists.
+	ReferencedBy map[int][]int
+
+	// NotSoftDeleted are the Versions not soft-deleted yet, for permanent deletes. If there are any, the permanent
+	// delete fails with a *DeleteError with code SubjectNotSoftDeleted or VersionNotSoftDeleted.
+	NotSoftDeleted []int
+}
+
+// DryRunDeleteSubject reports what DeleteSubject would remove with the same options, without deleting anything.
+func DryRunDeleteSubject(registry Registry, subject string, opts ...DeleteOption) (*DeleteReport, error) {
+	return dryRunDelete(subject, 0, true, opts, registry.SubjectVersions, registry.ReferencedBy)
+}
+
+// DryRunDeleteSubjectContext is like DryRunDeleteSubject, with a context-aware registry.
+func DryRunDeleteSubjectContext(ctx context.Context, registry ContextRegistry, subject string,
+	opts ...DeleteOption) (*DeleteReport, error) {
+	return dryRunDelete(subject, 0, true, opts, contextSubjectVersions(ctx, registry), contextReferencedBy(ctx, registry))
+}
+
+// DryRunDeleteSubjectVersion reports what DeleteSubjectVersion would remove with the same options, without deleting
+// anything. The version is resolved (Latest is the last version that would be deleted), and the report has no
+// versions if there is nothing to delete.
+func DryRunDeleteSubjectVersion(registry Registry, subject string, version int,
+	opts ...DeleteOption) (*DeleteReport, error) {
+	return dryRunDelete(subject, version, false, opts, registry.SubjectVersions, registry.ReferencedBy)
+}
+
+// DryRunDeleteSubjectVersionContext is like DryRunDeleteSubjectVersion, with a context-aware registry.
+func DryRunDeleteSubjectVersionContext(ctx context.Context, registry ContextRegistry, subject string, version int,
+	opts ...DeleteOption) (*DeleteReport, error) {
+	return dryRunDelete(subject, version, false, opts, contextSubjectVersions(ctx, registry),
+		contextReferencedBy(ctx, registry))
+}
+
+func contextSubjectVersions(ctx context.Context, registry ContextRegistry) func(string, ...ListOption) ([]int,
+	error) {
+	return func(subject string, opts ...ListOption) ([]int, error) {
+		return registry.SubjectVersionsContext(ctx, subject, opts...)
+	}
+}
+
+func contextReferencedBy(ctx context.Context, registry ContextRegistry) func(string, int) ([]int, error) {
+	return func(subject string, version int) ([]int, error) {
+		return registry.ReferencedByContext(ctx, subject, version)
+	}
+}
+
+// dryRunDelete reports the delete of version of subject (or of all its versions).
+func dryRunDelete(subject string, version int, allVersions bool, opts []DeleteOption,
+	subjectVersions func(string, ...ListOption) ([]int, error),
+	referencedBy func(string, int) ([]int, error)) (*DeleteReport, error) {
+	permanent := newDeleteOptions(opts).permanent
+	var listOpts []ListOption
+	if permanent {
+		listOpts = append(listOpts, IncludeDeleted())
+	}
+	versions, err := subjectVersions(subject, listOpts...)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &DeleteReport{Subject: subject, ReferencedBy: map[int][]int{}}
+	switch {
+	case allVersions:
+		report.Versions = versions
+	case version == Latest && len(versions) > 0:
+		report.Versions = []int{versions[len(versions)-1]}
+	default:
+		for _, v := range versions {
+			if v == version {
+				report.Versions = []int{v}
+			}
+		}
+	}
+
+	if permanent && len(report.Versions) > 0 {
+		live, err := subjectVersions(subject)
+		if err != nil && !isNotFound(err) {
+			return nil, err
+		}
+		for _, v := range report.Versions {
+			for _, l := range live {
+				if v == l {
+					report.NotSoftDeleted = append(report.NotSoftDeleted, v)
+				}
+			}
+		}
+	}
+
+	for _, v := range report.Versions {
+		ids, err := referencedBy(subject, v)
+		if isNotFound(err) {
+			// soft-deleted versions (or all the versions of a soft-deleted subject) are not found
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			report.ReferencedBy[v] = ids
+		}
+	}
+	return report, nil
+}
+
+// isNotFound reports whether err is an *APIError with code SubjectNotFound or VersionNotFound.
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && (apiErr.Code == SubjectNotFound || apiErr.Code == VersionNotFound)
+}
@@ -0,0 +1,204 @@
+package schemaregistry_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	testifymock "github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_DeleteSubjectOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		if r.URL.Query().Get("permanent") == "true" {
+			assert.Equal(t, "/subjects/frames-value?permanent=true", r.URL.String())
+		} else {
+			assert.Equal(t, "/subjects/frames-value", r.URL.String())
+		}
+
+		w.Write([]byte(`[1, 2, 3]`))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	versions, err := registry.DeleteSubject("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, versions)
+
+	versions, err = registry.DeleteSubject("frames-value", schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, versions)
+}
+
+func TestRegistry_DeleteSubjectVersionOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "DELETE", r.Method)
+		switch r.URL.String() {
+		case "/subjects/frames-value/versions/2":
+			w.Write([]byte(`2`))
+		case "/subjects/frames-value/versions/latest?permanent=true":
+			w.Write([]byte(`3`))
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	version, err := registry.DeleteSubjectVersion("frames-value", 2)
+	require.Nil(t, err)
+	assert.Equal(t, 2, version)
+
+	version, err = registry.DeleteSubjectVersion("frames-value", schemaregistry.Latest, schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, 3, version)
+}
+
+func TestRegistry_DeleteErrors(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.String() {
+		case "/subjects/frames-value?permanent=true":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code": 40405, "message": "Subject 'frames-value' was not deleted first"}`))
+		case "/subjects/frames-value/versions/2":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			w.Write([]byte(`{"error_code": 42206, "message": "One or more references exist to the schema"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error_code": 40401, "message": "Subject not found"}`))
+		}
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.DeleteSubject("frames-value", schemaregistry.Permanent())
+	deleteErr, ok := err.(*schemaregistry.DeleteError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, schemaregistry.SubjectNotSoftDeleted, deleteErr.Code)
+	assert.Equal(t, "frames-value", deleteErr.Subject)
+	assert.True(t, deleteErr.AllVersions)
+	assert.True(t, deleteErr.Permanent)
+	assert.EqualError(t, err, "error permanently deleting subject frames-value: Schema Registry API error, "+
+		"code: 40405 message: Subject 'frames-value' was not deleted first")
+	var apiErr *schemaregistry.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	_, err = registry.DeleteSubjectVersion("frames-value", 2)
+	deleteErr, ok = err.(*schemaregistry.DeleteError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, schemaregistry.ReferenceExists, deleteErr.Code)
+	assert.Equal(t, 2, deleteErr.Version)
+	assert.False(t, deleteErr.AllVersions)
+	assert.False(t, deleteErr.Permanent)
+	assert.EqualError(t, err, "error deleting subject frames-value version 2: Schema Registry API error, "+
+		"code: 42206 message: One or more references exist to the schema")
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+
+	// other errors aren't delete errors
+	_, err = registry.DeleteSubject("inexistent")
+	apiErr, ok = err.(*schemaregistry.APIError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, schemaregistry.SubjectNotFound, apiErr.Code)
+}
+
+func TestDryRunDeleteSubject(t *testing.T) {
+	t.Parallel()
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("SubjectVersions", "frames-value").Return([]int{2, 3}, nil)
+	mock.On("SubjectVersions", "frames-value", testifymock.Anything).Return([]int{1, 2, 3}, nil)
+	mock.On("ReferencedBy", "frames-value", 1).Return([]int(nil), &schemaregistry.APIError{
+		Code:    schemaregistry.VersionNotFound,
+		Message: "Version not found",
+	})
+	mock.On("ReferencedBy", "frames-value", 2).Return([]int{}, nil)
+	mock.On("ReferencedBy", "frames-value", 3).Return([]int{10, 11}, nil)
+
+	report, err := schemaregistry.DryRunDeleteSubject(mock, "frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, &schemaregistry.DeleteReport{
+		Subject:      "frames-value",
+		Versions:     []int{2, 3},
+		ReferencedBy: map[int][]int{3: {10, 11}},
+	}, report)
+
+	// permanent deletes remove the soft-deleted versions too
+	report, err = schemaregistry.DryRunDeleteSubject(mock, "frames-value", schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, report.Versions)
+	assert.Equal(t, map[int][]int{3: {10, 11}}, report.ReferencedBy)
+	// but only the soft-deleted ones can be
+	assert.Equal(t, []int{2, 3}, report.NotSoftDeleted)
+
+	report, err = schemaregistry.DryRunDeleteSubjectVersion(mock, "frames-value", schemaregistry.Latest)
+	require.Nil(t, err)
+	assert.Equal(t, []int{3}, report.Versions)
+
+	report, err = schemaregistry.DryRunDeleteSubjectVersion(mock, "frames-value", 2)
+	require.Nil(t, err)
+	assert.Equal(t, []int{2}, report.Versions)
+	assert.Empty(t, report.ReferencedBy)
+
+	// nothing to delete
+	report, err = schemaregistry.DryRunDeleteSubjectVersion(mock, "frames-value", 1)
+	require.Nil(t, err)
+	assert.Empty(t, report.Versions)
+
+	// nothing was deleted
+	mock.AssertNotCalled(t, "DeleteSubject", testifymock.Anything)
+	mock.AssertNotCalled(t, "DeleteSubjectVersion", testifymock.Anything, testifymock.Anything)
+}
+
+func TestDryRunDeletePermanent(t *testing.T) {
+	t.Parallel()
+	registry := schemaregistry.NewMemoryRegistry()
+	for _, schema := range []string{`"string"`, `"int"`} {
+		_, err := registry.RegisterSubjectSchema("frames-value", schema)
+		require.Nil(t, err)
+	}
+
+	// live versions must be soft-deleted first
+	report, err := schemaregistry.DryRunDeleteSubject(registry, "frames-value", schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, report.Versions)
+	assert.Equal(t, []int{1, 2}, report.NotSoftDeleted)
+	_, err = registry.DeleteSubject("frames-value", schemaregistry.Permanent())
+	assertAPIError(t, schemaregistry.SubjectNotSoftDeleted, err)
+
+	_, err = registry.DeleteSubjectVersion("frames-value", 2)
+	require.Nil(t, err)
+	report, err = schemaregistry.DryRunDeleteSubjectVersion(registry, "frames-value", 1, schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, []int{1}, report.NotSoftDeleted)
+	report, err = schemaregistry.DryRunDeleteSubjectVersion(registry, "frames-value", 2, schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, []int{2}, report.Versions)
+	assert.Empty(t, report.NotSoftDeleted)
+
+	// a soft-deleted subject can be permanently deleted
+	_, err = registry.DeleteSubject("frames-value")
+	require.Nil(t, err)
+	report, err = schemaregistry.DryRunDeleteSubject(registry, "frames-value", schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, report.Versions)
+	assert.Empty(t, report.NotSoftDeleted)
+	assert.Empty(t, report.ReferencedBy)
+	versions, err := registry.DeleteSubject("frames-value", schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, report.Versions, versions)
+}
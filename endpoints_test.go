@@ -0,0 +1,156 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingServer returns a server that counts its requests in calls and responds with status (and a subject list
+// if 200).
+func countingServer(calls *int32, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			json.NewEncoder(w).Encode([]string{"frames-value"})
+		}
+	}))
+}
+
+func TestNewMultipleEndpoints(t *testing.T) {
+	t.Parallel()
+	_, err := schemaregistry.New("http://sr1:8081, http://sr2:8081/,http://sr3:8081")
+	assert.Nil(t, err)
+
+	_, err = schemaregistry.New("http://sr1:8081,asdf")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid endpoint URL: asdf")
+	}
+}
+
+func TestRegistry_FailoverEndpointDown(t *testing.T) {
+	t.Parallel()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	var calls int32
+	up := countingServer(&calls, http.StatusOK)
+	defer up.Close()
+
+	registry, err := schemaregistry.New(down.URL + "," + up.URL)
+	require.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		subjects, err := registry.Subjects()
+		require.Nil(t, err)
+		assert.Equal(t, []string{"frames-value"}, subjects)
+	}
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_FailoverCooldown(t *testing.T) {
+	t.Parallel()
+	var failingCalls, healthyCalls, otherCalls int32
+	failing := countingServer(&failingCalls, http.StatusServiceUnavailable)
+	defer failing.Close()
+	healthy := countingServer(&healthyCalls, http.StatusOK)
+	defer healthy.Close()
+	other := countingServer(&otherCalls, http.StatusOK)
+	defer other.Close()
+
+	registry, err := schemaregistry.New(failing.URL+","+healthy.URL+","+other.URL,
+		schemaregistry.WithEndpointCooldown(100*time.Millisecond))
+	require.Nil(t, err)
+
+	_, err = registry.Subjects()
+	require.Nil(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&failingCalls))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&healthyCalls))
+
+	// during the cooldown, the failing endpoint is skipped
+	_, err = registry.Subjects()
+	require.Nil(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&failingCalls))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&healthyCalls))
+
+	// after the cooldown, it's preferred again
+	time.Sleep(150 * time.Millisecond)
+	_, err = registry.Subjects()
+	require.Nil(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&failingCalls))
+	assert.EqualValues(t, 3, atomic.LoadInt32(&healthyCalls))
+	assert.EqualValues(t, 0, atomic.LoadInt32(&otherCalls))
+}
+
+func TestRegistry_FailoverAllDown(t *testing.T) {
+	t.Parallel()
+	var calls1, calls2 int32
+	ts1 := countingServer(&calls1, http.StatusBadGateway)
+	defer ts1.Close()
+	ts2 := countingServer(&calls2, http.StatusBadGateway)
+	defer ts2.Close()
+
+	registry, err := schemaregistry.New(ts1.URL + "," + ts2.URL)
+	require.Nil(t, err)
+
+	_, err = registry.Subjects()
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls1))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls2))
+
+	// all unhealthy: still tried
+	_, err = registry.Subjects()
+	assert.Error(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls1))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls2))
+}
+
+func TestRegistry_FailoverClientErrorNotFailedOver(t *testing.T) {
+	t.Parallel()
+	var calls1, calls2 int32
+	ts1 := countingServer(&calls1, http.StatusNotFound)
+	defer ts1.Close()
+	ts2 := countingServer(&calls2, http.StatusOK)
+	defer ts2.Close()
+
+	registry, err := schemaregistry.New(ts1.URL + "," + ts2.URL)
+	require.Nil(t, err)
+
+	_, err = registry.Subjects()
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls1))
+	assert.EqualValues(t, 0, atomic.LoadInt32(&calls2))
+}
+
+func TestRegistry_FailoverNotIdempotent(t *testing.T) {
+	t.Parallel()
+	var calls1, calls2 int32
+	ts1 := countingServer(&calls1, http.StatusInternalServerError)
+	defer ts1.Close()
+	ts2 := countingServer(&calls2, http.StatusOK)
+	defer ts2.Close()
+
+	registry, err := schemaregistry.New(ts1.URL + "," + ts2.URL)
+	require.Nil(t, err)
+
+	// the DELETE may have been processed by the first endpoint, so it isn't sent to the second one
+	_, err = registry.DeleteSubjectConfig("frames-value")
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls1))
+	assert.EqualValues(t, 0, atomic.LoadInt32(&calls2))
+
+	// but the first endpoint is still marked unhealthy
+	_, err = registry.Subjects()
+	require.Nil(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls1))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls2))
+}
@@ -0,0 +1,31 @@
+package schemaregistry
+
+import "time"
+
+// FlightWaiters returns the number of callers waiting for the in-flight calls of a registry (as returned by New or
+// NewContextRegistry), CachedRegistry or OAuthTokenProvider.
+func FlightWaiters(reg interface{}) int {
+	var g *flightGroup
+	switch r := reg.(type) {
+	case *registry:
+		g = &r.flights
+	case *CachedRegistry:
+		g = &r.flights
+	case *OAuthTokenProvider:
+		g = &r.flights
+	default:
+		panic("no flights")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	waiters := 0
+	for _, call := range g.calls {
+		waiters += call.waiters
+	}
+	return waiters
+}
+
+// Backoff returns the wait of the policy after the failed attempt number attempt.
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	return p.backoff(attempt)
+}
@@ -0,0 +1,306 @@
+// Package jsonschema implements JSON serializers and deserializers of Kafka messages in the Confluent wire format,
+// with JSON Schema schemas managed by a schema registry. Payloads are optionally validated against their schemas.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	jsv "github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// ValidationError is the error of a payload that isn't valid for its schema.
+type ValidationError struct {
+	// Pointer is the JSON pointer (RFC 6901) of the failing field in the payload, like "/frames/0/id". It's empty if
+	// the failing value is the whole payload.
+	Pointer string
+
+	// Message describes the failure.
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid JSON payload at %q: %s", e.Pointer, e.Message)
+}
+
+// Serializer encodes values as JSON, in messages in the Confluent wire format. It's safe for concurrent use.
+type Serializer struct {
+	registry     schemaregistry.Registry
+	autoRegister bool
+	validate     bool
+	nameStrategy schemaregistry.SubjectNameStrategy
+
+	// ids caches the ids of the schemas by subject and schema (subjectSchema -> int)
+	ids sync.Map
+
+	// schemas caches the compiled schemas by their JSON (string -> *jsv.Schema)
+	schemas sync.Map
+}
+
+// SerializerOption configures a Serializer.
+type SerializerOption func(*Serializer)
+
+// AutoRegisterSchemas sets if the schemas are registered in the subjects (the default, like the
+// auto.register.schemas setting of the Confluent serializers). If disabled, the schemas must be already registered.
+func AutoRegisterSchemas(enabled bool) SerializerOption {
+	return func(s *Serializer) {
+		s.autoRegister = enabled
+	}
+}
+
+// ValidateBeforeSerializing makes the Serializer validate every payload against its schema, failing with a
+// *ValidationError if it isn't valid.
+func ValidateBeforeSerializing() SerializerOption {
+	return func(s *Serializer) {
+		s.validate = true
+	}
+}
+
+// WithSubjectNameStrategy sets the strategy naming the subjects of the topics in SerializeKey and SerializeValue.
+// The default is schemaregistry.TopicNameStrategy.
+func WithSubjectNameStrategy(strategy schemaregistry.SubjectNameStrategy) SerializerOption {
+	return func(s *Serializer) {
+		s.nameStrategy = strategy
+	}
+}
+
+// NewSerializer returns a Serializer of the schemas of registry.
+func NewSerializer(registry schemaregistry.Registry, opts ...SerializerOption) *Serializer {
+	s := &Serializer{
+		registry:     registry,
+		autoRegister: true,
+		nameStrategy: schemaregistry.TopicNameStrategy,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type subjectSchema struct {
+	subject string
+	schema  string
+}
+
+// Serialize encodes v as JSON (with encoding/json), registering or looking up the JSON schema in subject, and returns
+// the message in the wire format.
+func (s *Serializer) Serialize(subject string, schema string, v interface{}) ([]byte, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error encoding JSON value of subject %s", subject)
+	}
+	if s.validate {
+		compiled, err := s.compile(schema)
+		if err != nil {
+			return nil, err
+		}
+		err = validate(compiled, payload)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	id, err := s.schemaID(subject, schema)
+	if err != nil {
+		return nil, err
+	}
+	message := make([]byte, 0, schemaregistry.WireHeaderSize+len(payload))
+	message = schemaregistry.AppendWireHeader(message, id)
+	return append(message, payload...), nil
+}
+
+// SerializeKey is like Serialize, for a key of topic. The subject is named by the SubjectNameStrategy, with the title
+// of the schema as record name.
+func (s *Serializer) SerializeKey(topic string, schema string, v interface{}) ([]byte, error) {
+	return s.serializeTopic(topic, true, schema, v)
+}
+
+// SerializeValue is like Serialize, for a value of topic. The subject is named by the SubjectNameStrategy, with the
+// title of the schema as record name.
+func (s *Serializer) SerializeValue(topic string, schema string, v interface{}) ([]byte, error) {
+	return s.serializeTopic(topic, false, schema, v)
+}
+
+func (s *Serializer) serializeTopic(topic string, isKey bool, schema string, v interface{}) ([]byte, error) {
+	var titled struct {
+		Title string `json:"title"`
+	}
+	// schemas may also be booleans, without title
+	json.Unmarshal([]byte(schema), &titled)
+	subject, err := s.nameStrategy.Subject(topic, isKey, titled.Title)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error naming subject of topic %s", topic)
+	}
+	return s.Serialize(subject, schema, v)
+}
+
+// schemaID returns the id of schema in subject.
+func (s *Serializer) schemaID(subject string, schema string) (int, error) {
+	key := subjectSchema{subject: subject, schema: schema}
+	if id, ok := s.ids.Load(key); ok {
+		return id.(int), nil
+	}
+
+	jsonType := schemaregistry.WithSchemaType(schemaregistry.JSON)
+	var id int
+	if s.autoRegister {
+		var err error
+		id, err = s.registry.RegisterSubjectSchema(subject, schema, jsonType)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		ss, err := s.registry.CheckSubjectSchema(subject, schema, jsonType)
+		if err != nil {
+			return 0, err
+		}
+		id = ss.ID
+	}
+	s.ids.Store(key, id)
+	return id, nil
+}
+
+func (s *Serializer) compile(schema string) (*jsv.Schema, error) {
+	if compiled, ok := s.schemas.Load(schema); ok {
+		return compiled.(*jsv.Schema), nil
+	}
+	compiled, err := compile("mem://schemas/serialized.json", schema, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.schemas.Store(schema, compiled)
+	return compiled, nil
+}
+
+// Deserializer decodes JSON messages in the wire format. It's safe for concurrent use.
+type Deserializer struct {
+	registry schemaregistry.Registry
+	validate bool
+
+	// schemas caches the compiled writer schemas by id (int -> *jsv.Schema)
+	schemas sync.Map
+}
+
+// DeserializerOption configures a Deserializer.
+type DeserializerOption func(*Deserializer)
+
+// ValidateAfterDeserializing makes the Deserializer validate every payload against its writer schema (with the
+// schemas it references), failing with a *ValidationError if it isn't valid.
+func ValidateAfterDeserializing() DeserializerOption {
+	return func(d *Deserializer) {
+		d.validate = true
+	}
+}
+
+// NewDeserializer returns a Deserializer of the schemas of registry. The registry is only used to validate the
+// payloads.
+func NewDeserializer(registry schemaregistry.Registry, opts ...DeserializerOption) *Deserializer {
+	d := &Deserializer{
+		registry: registry,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Deserialize decodes the JSON payload of message into v (with encoding/json).
+func (d *Deserializer) Deserialize(message []byte, v interface{}) error {
+	id, payload, err := schemaregistry.ParseWire(message)
+	if err != nil {
+		return err
+	}
+	if d.validate {
+		schema, err := d.schema(id)
+		if err != nil {
+			return err
+		}
+		err = validate(schema, payload)
+		if err != nil {
+			return err
+		}
+	}
+	err = json.Unmarshal(payload, v)
+	if err != nil {
+		return errors.Wrapf(err, "error decoding JSON message of schema %d", id)
+	}
+	return nil
+}
+
+// schema returns the compiled writer schema of id.
+func (d *Deserializer) schema(id int) (*jsv.Schema, error) {
+	if schema, ok := d.schemas.Load(id); ok {
+		return schema.(*jsv.Schema), nil
+	}
+	graph, err := schemaregistry.ResolveReferences(d.registry, id)
+	if err != nil {
+		return nil, err
+	}
+	if graph.Schema.SchemaType != schemaregistry.JSON {
+		return nil, errors.Errorf("schema %d is %s, not JSON", id, graph.Schema.SchemaType)
+	}
+	schema, err := compile("mem://schemas/"+strconv.Itoa(id)+".json", graph.Schema.Schema, graph.References)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid writer schema %d", id)
+	}
+	d.schemas.Store(id, schema)
+	return schema, nil
+}
+
+// compile compiles schema at location, with the referenced schemas at their names (relative to location).
+func compile(location string, schema string, references []*schemaregistry.ResolvedReference) (*jsv.Schema, error) {
+	base, err := url.Parse(location)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid schema location %s", location)
+	}
+	compiler := jsv.NewCompiler()
+	// the default of the Confluent clients
+	compiler.Draft = jsv.Draft7
+	err = compiler.AddResource(location, strings.NewReader(schema))
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JSON schema")
+	}
+	for _, reference := range references {
+		name, err := url.Parse(reference.Name)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid reference name %s", reference.Name)
+		}
+		err = compiler.AddResource(base.ResolveReference(name).String(), strings.NewReader(reference.Schema.Schema))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid JSON schema of reference %s", reference.Name)
+		}
+	}
+	compiled, err := compiler.Compile(location)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid JSON schema")
+	}
+	return compiled, nil
+}
+
+// validate validates the JSON payload against schema, returning a *ValidationError of the first failing field.
+func validate(schema *jsv.Schema, payload []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var v interface{}
+	err := decoder.Decode(&v)
+	if err != nil {
+		return errors.Wrap(err, "invalid JSON payload")
+	}
+
+	err = schema.Validate(v)
+	if validationErr, ok := err.(*jsv.ValidationError); ok {
+		// the causes of the failure are nested, down to the failing field
+		for len(validationErr.Causes) > 0 {
+			validationErr = validationErr.Causes[0]
+		}
+		return &ValidationError{Pointer: validationErr.InstanceLocation, Message: validationErr.Message}
+	}
+	return err
+}
@@ -0,0 +1,190 @@
+package jsonschema_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/larixsource/go-schema-registry/jsonschema"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const frameSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "title": "Frame",
+  "type": "object",
+  "properties": {
+    "id": {"type": "integer", "minimum": 0},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["id"]
+}`
+
+type frame struct {
+	ID   int64    `json:"id"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+func TestSerializer_AutoRegister(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`{"id": 7}`))
+	}))
+	defer server.Close()
+	registry, err := schemaregistry.New(server.URL)
+	require.Nil(t, err)
+
+	serializer := jsonschema.NewSerializer(registry, jsonschema.ValidateBeforeSerializing())
+	var message []byte
+	for i := 0; i < 3; i++ {
+		message, err = serializer.Serialize("frames-value", frameSchema, &frame{ID: 42})
+		require.Nil(t, err)
+	}
+	assert.Equal(t, append([]byte{0, 0, 0, 0, 7}, `{"id":42}`...), message)
+
+	// registered once, as a JSON schema
+	require.Len(t, requests, 1)
+	assert.True(t, strings.HasPrefix(requests[0], "POST /subjects/frames-value/versions {"), requests[0])
+	assert.Contains(t, requests[0], `"schemaType":"JSON"`)
+
+	var decoded frame
+	require.Nil(t, jsonschema.NewDeserializer(registry).Deserialize(message, &decoded))
+	assert.Equal(t, frame{ID: 42}, decoded)
+}
+
+func TestSerializer_NoAutoRegister(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("CheckSubjectSchema", "frames-value", frameSchema, mock.Anything).Return(&schemaregistry.SubjectSchema{
+		Subject:    "frames-value",
+		ID:         7,
+		Version:    2,
+		Schema:     frameSchema,
+		SchemaType: schemaregistry.JSON,
+	}, nil).Once()
+
+	serializer := jsonschema.NewSerializer(registry, jsonschema.AutoRegisterSchemas(false))
+	message, err := serializer.Serialize("frames-value", frameSchema, &frame{ID: 42})
+	require.Nil(t, err)
+	assert.Equal(t, append([]byte{0, 0, 0, 0, 7}, `{"id":42}`...), message)
+	registry.AssertNotCalled(t, "RegisterSubjectSchema", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestSerializer_SerializeValue(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("RegisterSubjectSchema", "frames-value", frameSchema, mock.Anything).Return(7, nil)
+	registry.On("RegisterSubjectSchema", "frames-Frame", frameSchema, mock.Anything).Return(8, nil)
+
+	message, err := jsonschema.NewSerializer(registry).SerializeValue("frames", frameSchema, &frame{ID: 42})
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 7}, message[:5])
+
+	serializer := jsonschema.NewSerializer(registry,
+		jsonschema.WithSubjectNameStrategy(schemaregistry.TopicRecordNameStrategy))
+	message, err = serializer.SerializeValue("frames", frameSchema, &frame{ID: 42})
+	require.Nil(t, err)
+	assert.Equal(t, []byte{0, 0, 0, 0, 8}, message[:5])
+
+	// schemas without title have no record name
+	_, err = serializer.SerializeValue("frames", `{"type": "object"}`, &frame{ID: 42})
+	assert.Equal(t, schemaregistry.ErrNoRecordName, errors.Cause(err))
+}
+
+func TestSerializer_Validation(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	serializer := jsonschema.NewSerializer(registry, jsonschema.ValidateBeforeSerializing())
+
+	tests := []struct {
+		name    string
+		v       interface{}
+		pointer string
+	}{
+		{name: "field", v: &frame{ID: -1}, pointer: "/id"},
+		{name: "nested", v: map[string]interface{}{"id": 1, "tags": []interface{}{"a", 2}}, pointer: "/tags/1"},
+		{name: "required", v: map[string]interface{}{}, pointer: ""},
+		{name: "root", v: "frame", pointer: ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := serializer.Serialize("frames-value", frameSchema, test.v)
+			validationErr, ok := err.(*jsonschema.ValidationError)
+			require.True(t, ok, "%v", err)
+			assert.Equal(t, test.pointer, validationErr.Pointer)
+			assert.NotEmpty(t, validationErr.Message)
+		})
+	}
+	// invalid payloads aren't registered
+	registry.AssertNotCalled(t, "RegisterSubjectSchema", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeserializer_Validation(t *testing.T) {
+	const orderSchema = `{
+  "title": "Order",
+  "type": "object",
+  "properties": {
+    "id": {"type": "integer"},
+    "frame": {"$ref": "frame.json"}
+  }
+}`
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("SchemaByID", 9).Return(&schemaregistry.SubjectSchema{
+		ID:         9,
+		Schema:     orderSchema,
+		SchemaType: schemaregistry.JSON,
+		References: []schemaregistry.SchemaReference{{Name: "frame.json", Subject: "frame", Version: 1}},
+	}, nil).Once()
+	registry.On("SubjectVersion", "frame", 1).Return(&schemaregistry.SubjectSchema{
+		Subject:    "frame",
+		Version:    1,
+		ID:         7,
+		Schema:     frameSchema,
+		SchemaType: schemaregistry.JSON,
+	}, nil).Once()
+
+	deserializer := jsonschema.NewDeserializer(registry, jsonschema.ValidateAfterDeserializing())
+	var order map[string]interface{}
+	message := append([]byte{0, 0, 0, 0, 9}, `{"id": 1, "frame": {"id": 42, "tags": ["a"]}}`...)
+	require.Nil(t, deserializer.Deserialize(message, &order))
+	assert.Equal(t, map[string]interface{}{
+		"id":    float64(1),
+		"frame": map[string]interface{}{"id": float64(42), "tags": []interface{}{"a"}},
+	}, order)
+
+	// the referenced schema validates the nested fields, and the compiled schema is cached
+	message = append([]byte{0, 0, 0, 0, 9}, `{"id": 1, "frame": {"id": 42, "tags": ["a", 2]}}`...)
+	err := deserializer.Deserialize(message, &order)
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, "/frame/tags/1", validationErr.Pointer)
+	registry.AssertExpectations(t)
+}
+
+func TestDeserializer_Errors(t *testing.T) {
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("SchemaByID", 1).Return(&schemaregistry.SubjectSchema{
+		ID:     1,
+		Schema: frameSchema,
+	}, nil)
+	registry.On("SchemaByID", 2).Return(&schemaregistry.SubjectSchema{
+		ID:         2,
+		Schema:     `{"type": 1}`,
+		SchemaType: schemaregistry.JSON,
+	}, nil)
+
+	deserializer := jsonschema.NewDeserializer(registry, jsonschema.ValidateAfterDeserializing())
+	var v interface{}
+	assert.Equal(t, schemaregistry.ErrTruncatedMessage, deserializer.Deserialize([]byte{0, 0}, &v))
+	err := deserializer.Deserialize([]byte{0, 0, 0, 0, 1, '{', '}'}, &v)
+	assert.EqualError(t, err, "schema 1 is Avro, not JSON")
+	err = deserializer.Deserialize([]byte{0, 0, 0, 0, 2, '{', '}'}, &v)
+	assert.Error(t, err)
+	err = jsonschema.NewDeserializer(registry).Deserialize([]byte{0, 0, 0, 0, 2, '{'}, &v)
+	assert.Error(t, err)
+}
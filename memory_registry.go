@@ -0,0 +1,646 @@
+package schemaregistry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MemoryRegistry is an in-memory Registry, behaving like a real registry, for tests that would otherwise script every
+// call of a MockRegistry:
+//
+//   - schemas get globally unique ids, and identical schemas (same type, canonical schema and references) registered
+//     in several subjects share the id.
+//   - each subject has its own versions, which are soft-deleted and then permanently deleted like in the registry.
+//   - subjects have their own configs and modes, falling back to the global ones.
+//   - errors are *APIError instances with the codes and HTTP statuses of the registry, wrapped like the client does
+//     (*InvalidSchemaError, *DeleteError and *ModeError).
+//
+// Schemas aren't parsed (Avro and JSON Schema schemas must only be valid JSON), so compatibility is never checked:
+// every schema is compatible. A MemoryRegistry is safe for concurrent use.
+type MemoryRegistry struct {
+	mu sync.Mutex
+
+	// lastID is the last id assigned to a schema
+	lastID int
+
+	// schemas are the registered schemas by id
+	schemas map[int]*memorySchema
+
+	// ids are the ids of the registered schemas by key (memorySchema.key)
+	ids map[string]int
+
+	// subjects are the versions of each subject, in version order
+	subjects map[string][]*memoryVersion
+
+	config         Config
+	subjectConfigs map[string]Config
+
+	mode         Mode
+	subjectModes map[string]Mode
+}
+
+type memorySchema struct {
+	schema     string
+	schemaType SchemaType
+	references []SchemaReference
+}
+
+// key identifies identical schemas.
+func (s *memorySchema) key() string {
+	references, _ := json.Marshal(s.references)
+	return s.schemaType.String() + "\x00" + canonicalSchema(s.schema) + "\x00" + string(references)
+}
+
+type memoryVersion struct {
+	version int
+	id      int
+	deleted bool
+}
+
+// NewMemoryRegistry returns an empty MemoryRegistry, with Backward compatibility and ReadWrite mode.
+func NewMemoryRegistry() *MemoryRegistry {
+	return &MemoryRegistry{
+		schemas:        make(map[int]*memorySchema),
+		ids:            make(map[string]int),
+		subjects:       make(map[string][]*memoryVersion),
+		config:         Config{Compatibility: CompatibilityLevel(Backward)},
+		subjectConfigs: make(map[string]Config),
+		mode:           ReadWrite,
+		subjectModes:   make(map[string]Mode),
+	}
+}
+
+// memoryError returns an *APIError like the ones of the registry.
+func memoryError(code ErrorCode, format string, args ...interface{}) *APIError {
+	status := http.StatusNotFound
+	if code >= 42200 && code < 42300 {
+		status = http.StatusUnprocessableEntity
+	}
+	return &APIError{Code: code, Message: fmt.Sprintf(format, args...), StatusCode: status}
+}
+
+func subjectNotFound(subject string) error {
+	return memoryError(SubjectNotFound, "Subject '%s' not found.", subject)
+}
+
+func versionNotFound(version int) error {
+	return memoryError(VersionNotFound, "Version %s not found.", versionSegment(version))
+}
+
+func (m *MemoryRegistry) Schema(id int) (string, error) {
+	ss, err := m.SchemaByID(id)
+	if err != nil {
+		return "", err
+	}
+	return ss.Schema, nil
+}
+
+func (m *MemoryRegistry) SchemaByID(id int) (*SubjectSchema, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.schemas[id]
+	if !ok {
+		return nil, memoryError(SchemaNotFound, "Schema %d not found", id)
+	}
+	return s.subjectSchema(id), nil
+}
+
+func (s *memorySchema) subjectSchema(id int) *SubjectSchema {
+	return &SubjectSchema{
+		ID:         id,
+		Schema:     s.schema,
+		SchemaType: s.schemaType,
+		References: append([]SchemaReference(nil), s.references...),
+	}
+}
+
+func (m *MemoryRegistry) SchemaTypes() ([]SchemaType, error) {
+	return []SchemaType{Avro, Protobuf, JSON}, nil
+}
+
+func (m *MemoryRegistry) ReferencedBy(subject string, version int) ([]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, err := m.liveVersion(subject, version)
+	if err != nil {
+		return nil, err
+	}
+	ids := m.referencedBy(subject, v.version)
+	if ids == nil {
+		ids = []int{}
+	}
+	return ids, nil
+}
+
+// referencedBy returns the sorted ids of the schemas of the versions not deleted that reference version of subject.
+func (m *MemoryRegistry) referencedBy(subject string, version int) []int {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, versions := range m.subjects {
+		for _, v := range versions {
+			if v.deleted || seen[v.id] {
+				continue
+			}
+			for _, ref := range m.schemas[v.id].references {
+				if ref.Subject == subject && ref.Version == version {
+					seen[v.id] = true
+					ids = append(ids, v.id)
+					break
+				}
+			}
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+func (m *MemoryRegistry) Subjects(opts ...ListOption) ([]string, error) {
+	o := newListOptions(opts)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	subjects := []string{}
+	for subject, versions := range m.subjects {
+		if !strings.HasPrefix(subject, o.subjectPrefix) {
+			continue
+		}
+		for _, v := range versions {
+			if o.deleted || !v.deleted {
+				subjects = append(subjects, subject)
+				break
+			}
+		}
+	}
+	sort.Strings(subjects)
+	return subjects, nil
+}
+
+func (m *MemoryRegistry) SubjectVersions(subject string, opts ...ListOption) ([]int, error) {
+	o := newListOptions(opts)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var versions []int
+	for _, v := range m.subjects[subject] {
+		if o.deleted || !v.deleted {
+			versions = append(versions, v.version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil, subjectNotFound(subject)
+	}
+	return versions, nil
+}
+
+func (m *MemoryRegistry) SubjectVersion(subject string, version int) (*SubjectSchema, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, err := m.liveVersion(subject, version)
+	if err != nil {
+		return nil, err
+	}
+	return m.subjectSchema(subject, v), nil
+}
+
+func (m *MemoryRegistry) subjectSchema(subject string, v *memoryVersion) *SubjectSchema {
+	ss := m.schemas[v.id].subjectSchema(v.id)
+	ss.Subject = subject
+	ss.Version = v.version
+	return ss
+}
+
+// liveVersion returns version (or the latest one) of subject, if it's not deleted.
+func (m *MemoryRegistry) liveVersion(subject string, version int) (*memoryVersion, error) {
+	if version < 0 {
+		return nil, memoryError(InvalidVersion, "The specified version '%d' is not a valid version id.", version)
+	}
+	var found *memoryVersion
+	for _, v := range m.subjects[subject] {
+		if v.deleted {
+			continue
+		}
+		if v.version == version || version == Latest {
+			found = v
+		}
+	}
+	if found != nil {
+		return found, nil
+	}
+	if !m.hasLiveVersions(subject) {
+		return nil, subjectNotFound(subject)
+	}
+	return nil, versionNotFound(version)
+}
+
+func (m *MemoryRegistry) hasLiveVersions(subject string) bool {
+	for _, v := range m.subjects[subject] {
+		if !v.deleted {
+			return true
+		}
+	}
+	return false
+}
+
+// newMemorySchema returns the schema given to an operation, validating it like the registry.
+func (m *MemoryRegistry) newMemorySchema(schema string, opts []SchemaOption) (*memorySchema, *schemaJSON, error) {
+	msg := newSchemaJSON(schema, opts)
+	s := &memorySchema{schema: schema, schemaType: msg.SchemaType, references: msg.References}
+	invalid := func(format string, args ...interface{}) error {
+		return &InvalidSchemaError{APIError: memoryError(InvalidSchema, format, args...), SchemaType: msg.SchemaType}
+	}
+	if strings.TrimSpace(schema) == "" {
+		return nil, nil, invalid("Empty schema")
+	}
+	if msg.SchemaType != Protobuf && !json.Valid([]byte(schema)) {
+		return nil, nil, invalid("Invalid schema: %s", schema)
+	}
+	for _, ref := range msg.References {
+		if _, err := m.liveVersion(ref.Subject, ref.Version); err != nil {
+			return nil, nil, invalid("Invalid schema: reference %s (subject %s, version %d) not found", ref.Name,
+				ref.Subject, ref.Version)
+		}
+	}
+	return s, msg, nil
+}
+
+// subjectMode returns the mode of subject, or the global one.
+func (m *MemoryRegistry) subjectMode(subject string) Mode {
+	if mode, ok := m.subjectModes[subject]; ok && m.mode != ReadOnlyOverride {
+		return mode
+	}
+	return m.mode
+}
+
+// checkWritable returns a *ModeError if subject can't be modified in its mode.
+func (m *MemoryRegistry) checkWritable(subject string) error {
+	if mode := m.subjectMode(subject); mode == ReadOnly || mode == ReadOnlyOverride {
+		return &ModeError{memoryError(OperationNotPermitted, "Subject %s is in read-only mode", subject)}
+	}
+	return nil
+}
+
+func (m *MemoryRegistry) RegisterSubjectSchema(subject string, schema string, opts ...SchemaOption) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.checkWritable(subject); err != nil {
+		return 0, err
+	}
+	s, msg, err := m.newMemorySchema(schema, opts)
+	if err != nil {
+		return 0, err
+	}
+	importing := msg.ID != 0 || msg.Version != 0
+	if importing && m.subjectMode(subject) != Import {
+		return 0, &ModeError{memoryError(OperationNotPermitted, "Subject %s is not in import mode", subject)}
+	}
+
+	key := s.key()
+	id, registered := m.ids[key]
+	for _, v := range m.subjects[subject] {
+		if registered && !v.deleted && v.id == id && (msg.ID == 0 || msg.ID == id) &&
+			(msg.Version == 0 || msg.Version == v.version) {
+			// already registered in the subject
+			return id, nil
+		}
+	}
+
+	switch {
+	case msg.ID != 0 && registered && msg.ID != id:
+		return 0, &ModeError{memoryError(OperationNotPermitted,
+			"Schema already registered with id %d instead of input id %d", id, msg.ID)}
+	case msg.ID != 0 && !registered:
+		if _, taken := m.schemas[msg.ID]; taken {
+			return 0, &ModeError{memoryError(OperationNotPermitted,
+				"Overwrite new schema with id %d is not permitted.", msg.ID)}
+		}
+		id = msg.ID
+	case !registered:
+		id = m.lastID + 1
+		for m.schemas[id] != nil {
+			id++
+		}
+	}
+
+	versions := m.subjects[subject]
+	version := msg.Version
+	if version == 0 {
+		version = 1
+		if len(versions) > 0 {
+			version = versions[len(versions)-1].version + 1
+		}
+	}
+	for _, v := range versions {
+		if v.version == version {
+			return 0, &ModeError{memoryError(OperationNotPermitted,
+				"Overwrite new schema with version %d is not permitted.", version)}
+		}
+	}
+
+	if !registered {
+		m.schemas[id] = s
+		m.ids[key] = id
+		if id > m.lastID {
+			m.lastID = id
+		}
+	}
+	versions = append(versions, &memoryVersion{version: version, id: id})
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].version < versions[j].version
+	})
+	m.subjects[subject] = versions
+	return id, nil
+}
+
+func (m *MemoryRegistry) CheckSubjectSchema(subject string, schema string, opts ...SchemaOption) (*SubjectSchema,
+	error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.hasLiveVersions(subject) {
+		return nil, subjectNotFound(subject)
+	}
+	s, _, err := m.newMemorySchema(schema, opts)
+	if err != nil {
+		return nil, err
+	}
+	if id, ok := m.ids[s.key()]; ok {
+		for _, v := range m.subjects[subject] {
+			if !v.deleted && v.id == id {
+				return m.subjectSchema(subject, v), nil
+			}
+		}
+	}
+	return nil, memoryError(SchemaNotFound, "Schema not found")
+}
+
+func (m *MemoryRegistry) TestCompatibility(subject string, version int, schema string, opts ...SchemaOption) (bool,
+	error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, err := m.liveVersion(subject, version); err != nil {
+		return false, err
+	}
+	if _, _, err := m.newMemorySchema(schema, opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (m *MemoryRegistry) TestCompatibilityAll(subject string, schema string,
+	opts ...SchemaOption) (*CompatibilityResult, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, _, err := m.newMemorySchema(schema, opts); err != nil {
+		return nil, err
+	}
+	return &CompatibilityResult{IsCompatible: true}, nil
+}
+
+func (m *MemoryRegistry) DeleteSubject(subject string, opts ...DeleteOption) ([]int, error) {
+	permanent := newDeleteOptions(opts).permanent
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	versions := m.subjects[subject]
+	if len(versions) == 0 {
+		return nil, subjectNotFound(subject)
+	}
+	if err := m.checkWritable(subject); err != nil {
+		return nil, err
+	}
+	fail := func(code ErrorCode, format string, args ...interface{}) ([]int, error) {
+		return nil, deleteError(memoryError(code, format, args...), subject, 0, true, permanent)
+	}
+
+	live := m.hasLiveVersions(subject)
+	switch {
+	case permanent && live:
+		return fail(SubjectNotSoftDeleted, "Subject '%s' was not deleted first before being permanently deleted",
+			subject)
+	case !permanent && !live:
+		return fail(SubjectSoftDeleted, "Subject '%s' was soft deleted.Set permanent=true to delete permanently",
+			subject)
+	}
+	for _, v := range versions {
+		if !v.deleted && len(m.referencedBy(subject, v.version)) > 0 {
+			return fail(ReferenceExists, "One or more references exist to the schema {subject=%s,version=%d}",
+				subject, v.version)
+		}
+	}
+
+	deleted := []int{}
+	for _, v := range versions {
+		if permanent || !v.deleted {
+			deleted = append(deleted, v.version)
+		}
+		v.deleted = true
+	}
+	if permanent {
+		delete(m.subjects, subject)
+		delete(m.subjectConfigs, subject)
+		delete(m.subjectModes, subject)
+		m.removeUnused(versions)
+	}
+	return deleted, nil
+}
+
+func (m *MemoryRegistry) DeleteSubjectVersion(subject string, version int, opts ...DeleteOption) (int, error) {
+	permanent := newDeleteOptions(opts).permanent
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	versions := m.subjects[subject]
+	if len(versions) == 0 {
+		return 0, subjectNotFound(subject)
+	}
+	if err := m.checkWritable(subject); err != nil {
+		return 0, err
+	}
+	fail := func(code ErrorCode, format string, args ...interface{}) (int, error) {
+		return 0, deleteError(memoryError(code, format, args...), subject, version, false, permanent)
+	}
+
+	i := -1
+	for j, v := range versions {
+		if v.version == version || (version == Latest && (permanent || !v.deleted)) {
+			i = j
+		}
+	}
+	if i < 0 {
+		return 0, versionNotFound(version)
+	}
+	v := versions[i]
+	switch {
+	case permanent && !v.deleted:
+		return fail(VersionNotSoftDeleted,
+			"Subject '%s' Version %d was not deleted first before being permanently deleted", subject, v.version)
+	case !permanent && v.deleted:
+		return fail(VersionSoftDeleted,
+			"Subject '%s' Version %d was soft deleted.Set permanent=true to delete permanently", subject, v.version)
+	case !v.deleted && len(m.referencedBy(subject, v.version)) > 0:
+		return fail(ReferenceExists, "One or more references exist to the schema {subject=%s,version=%d}",
+			subject, v.version)
+	}
+
+	v.deleted = true
+	if permanent {
+		m.subjects[subject] = append(versions[:i:i], versions[i+1:]...)
+		if len(m.subjects[subject]) == 0 {
+			delete(m.subjects, subject)
+		}
+		m.removeUnused([]*memoryVersion{v})
+	}
+	return v.version, nil
+}
+
+// removeUnused removes the schemas of the permanently deleted versions that no other version uses.
+func (m *MemoryRegistry) removeUnused(deleted []*memoryVersion) {
+	used := make(map[int]bool)
+	for _, versions := range m.subjects {
+		for _, v := range versions {
+			used[v.id] = true
+		}
+	}
+	for _, v := range deleted {
+		if s, ok := m.schemas[v.id]; ok && !used[v.id] {
+			delete(m.ids, s.key())
+			delete(m.schemas, v.id)
+		}
+	}
+}
+
+func (m *MemoryRegistry) SetConfig(config *Config) (*Config, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.config = mergeConfig(m.config, config)
+	updated := m.config
+	return &updated, nil
+}
+
+func (m *MemoryRegistry) Config() (*Config, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	config := m.config
+	return &config, nil
+}
+
+func (m *MemoryRegistry) SetSubjectConfig(subject string, config *Config) (*Config, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	updated := mergeConfig(m.subjectConfigs[subject], config)
+	m.subjectConfigs[subject] = updated
+	return &updated, nil
+}
+
+func (m *MemoryRegistry) SubjectConfig(subject string) (*Config, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	config, ok := m.subjectConfigs[subject]
+	if !ok {
+		return nil, memoryError(SubjectLevelCompatibilityNotConfigured,
+			"Subject '%s' does not have subject-level compatibility configured", subject)
+	}
+	return &config, nil
+}
+
+func (m *MemoryRegistry) DeleteSubjectConfig(subject string) (*Config, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	config, ok := m.subjectConfigs[subject]
+	if !ok {
+		return nil, memoryError(SubjectLevelCompatibilityNotConfigured,
+			"Subject '%s' does not have subject-level compatibility configured", subject)
+	}
+	delete(m.subjectConfigs, subject)
+	return &config, nil
+}
+
+// EffectiveConfig returns the config applying to subject: its own config, or the global one. A subject config without
+// compatibility level uses the global level.
+func (m *MemoryRegistry) EffectiveConfig(subject string) *Config {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	config, ok := m.subjectConfigs[subject]
+	if !ok {
+		config = m.config
+	}
+	if config.Compatibility == nil {
+		config.Compatibility = m.config.Compatibility
+	}
+	return &config
+}
+
+// mergeConfig applies the update to config, keeping the fields the update doesn't set.
+func mergeConfig(config Config, update *Config) Config {
+	if update.Compatibility != nil {
+		config.Compatibility = update.Compatibility
+	}
+	if update.Normalize != nil {
+		config.Normalize = update.Normalize
+	}
+	if update.Alias != "" {
+		config.Alias = update.Alias
+	}
+	if update.CompatibilityGroup != "" {
+		config.CompatibilityGroup = update.CompatibilityGroup
+	}
+	if update.DefaultMetadata != nil {
+		config.DefaultMetadata = update.DefaultMetadata
+	}
+	if update.OverrideRuleSet != nil {
+		config.OverrideRuleSet = update.OverrideRuleSet
+	}
+	return config
+}
+
+func (m *MemoryRegistry) SetMode(mode Mode, opts ...ModeOption) (Mode, error) {
+	force := newModeOptions(opts).force
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mode == Import && !force && len(m.subjects) > 0 {
+		return 0, &ModeError{memoryError(OperationNotPermitted,
+			"Cannot import since found existing subjects")}
+	}
+	m.mode = mode
+	return mode, nil
+}
+
+func (m *MemoryRegistry) Mode() (Mode, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mode, nil
+}
+
+func (m *MemoryRegistry) SetSubjectMode(subject string, mode Mode, opts ...ModeOption) (Mode, error) {
+	force := newModeOptions(opts).force
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if mode == Import && !force && len(m.subjects[subject]) > 0 {
+		return 0, &ModeError{memoryError(OperationNotPermitted,
+			"Cannot import since found existing subjects")}
+	}
+	m.subjectModes[subject] = mode
+	return mode, nil
+}
+
+func (m *MemoryRegistry) SubjectMode(subject string) (Mode, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mode, ok := m.subjectModes[subject]
+	if !ok {
+		return 0, memoryError(SubjectLevelModeNotConfigured,
+			"Subject '%s' does not have subject-level mode configured", subject)
+	}
+	return mode, nil
+}
+
+func (m *MemoryRegistry) DeleteSubjectMode(subject string) (Mode, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mode, ok := m.subjectModes[subject]
+	if !ok {
+		return 0, memoryError(SubjectLevelModeNotConfigured,
+			"Subject '%s' does not have subject-level mode configured", subject)
+	}
+	delete(m.subjectModes, subject)
+	return mode, nil
+}
@@ -0,0 +1,348 @@
+package schemaregistry_test
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const memoryTestSchema2 = `{"type": "record", "name": "Frame", "fields": [{"name": "id", "type": "long"}]}`
+
+// assertAPIError asserts that err is (or wraps) an *APIError with code.
+func assertAPIError(t *testing.T, code schemaregistry.ErrorCode, err error) {
+	var apiErr *schemaregistry.APIError
+	require.True(t, errors.As(err, &apiErr), "%v", err)
+	assert.Equal(t, code, apiErr.Code)
+}
+
+func TestMemoryRegistry_Register(t *testing.T) {
+	t.Parallel()
+	var registry schemaregistry.Registry = schemaregistry.NewMemoryRegistry()
+
+	id, err := registry.RegisterSubjectSchema("frames-value", testSchema)
+	require.Nil(t, err)
+	assert.Equal(t, 1, id)
+
+	// identical schemas share the id, and aren't registered twice in a subject
+	id, err = registry.RegisterSubjectSchema("frames-value", testSchema)
+	require.Nil(t, err)
+	assert.Equal(t, 1, id)
+	id, err = registry.RegisterSubjectSchema("other-value", testSchema)
+	require.Nil(t, err)
+	assert.Equal(t, 1, id)
+	id, err = registry.RegisterSubjectSchema("frames-value", memoryTestSchema2)
+	require.Nil(t, err)
+	assert.Equal(t, 2, id)
+	// the same schema of another type is another schema
+	id, err = registry.RegisterSubjectSchema("frames-value", memoryTestSchema2,
+		schemaregistry.WithSchemaType(schemaregistry.JSON))
+	require.Nil(t, err)
+	assert.Equal(t, 3, id)
+
+	versions, err := registry.SubjectVersions("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, versions)
+	subjects, err := registry.Subjects()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"frames-value", "other-value"}, subjects)
+	subjects, err = registry.Subjects(schemaregistry.SubjectPrefix("oth"))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"other-value"}, subjects)
+
+	ss, err := registry.SubjectVersion("frames-value", schemaregistry.Latest)
+	require.Nil(t, err)
+	assert.Equal(t, &schemaregistry.SubjectSchema{
+		Subject:    "frames-value",
+		Version:    3,
+		ID:         3,
+		Schema:     memoryTestSchema2,
+		SchemaType: schemaregistry.JSON,
+	}, ss)
+	ss, err = registry.CheckSubjectSchema("other-value", testSchema)
+	require.Nil(t, err)
+	assert.Equal(t, 1, ss.Version)
+	assert.Equal(t, 1, ss.ID)
+	schema, err := registry.Schema(2)
+	require.Nil(t, err)
+	assert.Equal(t, memoryTestSchema2, schema)
+	compatible, err := registry.TestCompatibility("frames-value", 1, memoryTestSchema2)
+	require.Nil(t, err)
+	assert.True(t, compatible)
+}
+
+func TestMemoryRegistry_Errors(t *testing.T) {
+	t.Parallel()
+	registry := schemaregistry.NewMemoryRegistry()
+	_, err := registry.RegisterSubjectSchema("frames-value", testSchema)
+	require.Nil(t, err)
+
+	_, err = registry.Schema(42)
+	assertAPIError(t, schemaregistry.SchemaNotFound, err)
+	_, err = registry.SubjectVersions("inexistent")
+	assertAPIError(t, schemaregistry.SubjectNotFound, err)
+	_, err = registry.SubjectVersion("inexistent", 1)
+	assertAPIError(t, schemaregistry.SubjectNotFound, err)
+	_, err = registry.SubjectVersion("frames-value", 2)
+	assertAPIError(t, schemaregistry.VersionNotFound, err)
+	apiErr := err.(*schemaregistry.APIError)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	_, err = registry.SubjectVersion("frames-value", -2)
+	assertAPIError(t, schemaregistry.InvalidVersion, err)
+	_, err = registry.CheckSubjectSchema("inexistent", testSchema)
+	assertAPIError(t, schemaregistry.SubjectNotFound, err)
+	_, err = registry.CheckSubjectSchema("frames-value", memoryTestSchema2)
+	assertAPIError(t, schemaregistry.SchemaNotFound, err)
+
+	_, err = registry.RegisterSubjectSchema("frames-value", "{not json")
+	invalidErr, ok := err.(*schemaregistry.InvalidSchemaError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, schemaregistry.InvalidSchema, invalidErr.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, invalidErr.StatusCode)
+	_, err = registry.RegisterSubjectSchema("orders-value", memoryTestSchema2, schemaregistry.WithReferences(
+		schemaregistry.SchemaReference{Name: "Address", Subject: "address", Version: 1}))
+	assertAPIError(t, schemaregistry.InvalidSchema, err)
+}
+
+func TestMemoryRegistry_Delete(t *testing.T) {
+	t.Parallel()
+	registry := schemaregistry.NewMemoryRegistry()
+	_, err := registry.RegisterSubjectSchema("frames-value", testSchema)
+	require.Nil(t, err)
+	_, err = registry.RegisterSubjectSchema("frames-value", memoryTestSchema2)
+	require.Nil(t, err)
+
+	// versions are soft-deleted, then permanently deleted
+	_, err = registry.DeleteSubjectVersion("frames-value", 1, schemaregistry.Permanent())
+	assertAPIError(t, schemaregistry.VersionNotSoftDeleted, err)
+	version, err := registry.DeleteSubjectVersion("frames-value", 1)
+	require.Nil(t, err)
+	assert.Equal(t, 1, version)
+	_, err = registry.DeleteSubjectVersion("frames-value", 1)
+	assertAPIError(t, schemaregistry.VersionSoftDeleted, err)
+	_, err = registry.SubjectVersion("frames-value", 1)
+	assertAPIError(t, schemaregistry.VersionNotFound, err)
+	versions, err := registry.SubjectVersions("frames-value", schemaregistry.IncludeDeleted())
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2}, versions)
+	// soft-deleted schemas are still got by id
+	_, err = registry.Schema(1)
+	require.Nil(t, err)
+	version, err = registry.DeleteSubjectVersion("frames-value", 1, schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, 1, version)
+	_, err = registry.Schema(1)
+	assertAPIError(t, schemaregistry.SchemaNotFound, err)
+
+	_, err = registry.DeleteSubject("frames-value", schemaregistry.Permanent())
+	deleteErr, ok := err.(*schemaregistry.DeleteError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, schemaregistry.SubjectNotSoftDeleted, deleteErr.Code)
+	versions, err = registry.DeleteSubject("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, []int{2}, versions)
+	_, err = registry.DeleteSubject("frames-value")
+	assertAPIError(t, schemaregistry.SubjectSoftDeleted, err)
+	subjects, err := registry.Subjects()
+	require.Nil(t, err)
+	assert.Empty(t, subjects)
+	subjects, err = registry.Subjects(schemaregistry.IncludeDeleted())
+	require.Nil(t, err)
+	assert.Equal(t, []string{"frames-value"}, subjects)
+
+	// new versions follow the soft-deleted ones
+	id, err := registry.RegisterSubjectSchema("frames-value", memoryTestSchema2)
+	require.Nil(t, err)
+	assert.Equal(t, 2, id)
+	ss, err := registry.SubjectVersion("frames-value", schemaregistry.Latest)
+	require.Nil(t, err)
+	assert.Equal(t, 3, ss.Version)
+
+	_, err = registry.DeleteSubject("frames-value")
+	require.Nil(t, err)
+	versions, err = registry.DeleteSubject("frames-value", schemaregistry.Permanent())
+	require.Nil(t, err)
+	assert.Equal(t, []int{2, 3}, versions)
+	_, err = registry.DeleteSubject("frames-value")
+	assertAPIError(t, schemaregistry.SubjectNotFound, err)
+}
+
+func TestMemoryRegistry_References(t *testing.T) {
+	t.Parallel()
+	registry := schemaregistry.NewMemoryRegistry()
+	_, err := registry.RegisterSubjectSchema("address", `{"type": "string"}`)
+	require.Nil(t, err)
+	ref := schemaregistry.WithReferences(schemaregistry.SchemaReference{Name: "Address", Subject: "address", Version: 1})
+	id, err := registry.RegisterSubjectSchema("orders-value", memoryTestSchema2, ref)
+	require.Nil(t, err)
+	assert.Equal(t, 2, id)
+
+	ids, err := registry.ReferencedBy("address", 1)
+	require.Nil(t, err)
+	assert.Equal(t, []int{2}, ids)
+
+	_, err = registry.DeleteSubjectVersion("address", 1)
+	assertAPIError(t, schemaregistry.ReferenceExists, err)
+	_, err = registry.DeleteSubject("address")
+	assertAPIError(t, schemaregistry.ReferenceExists, err)
+
+	graph, err := schemaregistry.ResolveReferences(registry, id)
+	require.Nil(t, err)
+	require.Len(t, graph.References, 1)
+	assert.Equal(t, `{"type": "string"}`, graph.References[0].Schema.Schema)
+
+	_, err = registry.DeleteSubject("orders-value")
+	require.Nil(t, err)
+	_, err = registry.DeleteSubject("address")
+	require.Nil(t, err)
+}
+
+func TestMemoryRegistry_Config(t *testing.T) {
+	t.Parallel()
+	registry := schemaregistry.NewMemoryRegistry()
+
+	config, err := registry.Config()
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Backward, *config.Compatibility)
+	_, err = registry.SubjectConfig("frames-value")
+	assertAPIError(t, schemaregistry.SubjectLevelCompatibilityNotConfigured, err)
+	assert.Equal(t, schemaregistry.Backward, *registry.EffectiveConfig("frames-value").Compatibility)
+
+	_, err = registry.SetSubjectConfig("frames-value",
+		&schemaregistry.Config{Compatibility: schemaregistry.CompatibilityLevel(schemaregistry.Full)})
+	require.Nil(t, err)
+	_, err = registry.SetConfig(
+		&schemaregistry.Config{Compatibility: schemaregistry.CompatibilityLevel(schemaregistry.None)})
+	require.Nil(t, err)
+	config, err = registry.SubjectConfig("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Full, *config.Compatibility)
+	assert.Equal(t, schemaregistry.Full, *registry.EffectiveConfig("frames-value").Compatibility)
+
+	config, err = registry.DeleteSubjectConfig("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Full, *config.Compatibility)
+	assert.Equal(t, schemaregistry.None, *registry.EffectiveConfig("frames-value").Compatibility)
+	_, err = registry.DeleteSubjectConfig("frames-value")
+	assertAPIError(t, schemaregistry.SubjectLevelCompatibilityNotConfigured, err)
+}
+
+func TestMemoryRegistry_ConfigPartial(t *testing.T) {
+	t.Parallel()
+	registry := schemaregistry.NewMemoryRegistry()
+	normalize := true
+
+	// updates without compatibility level keep the current one
+	config, err := registry.SetConfig(&schemaregistry.Config{Normalize: &normalize})
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Backward, *config.Compatibility)
+	assert.True(t, *config.Normalize)
+
+	_, err = registry.SetSubjectConfig("frames-value", &schemaregistry.Config{CompatibilityGroup: "frames"})
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Backward, *registry.EffectiveConfig("frames-value").Compatibility)
+	config, err = registry.SetSubjectConfig("frames-value",
+		&schemaregistry.Config{Compatibility: schemaregistry.CompatibilityLevel(schemaregistry.Full)})
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Full, *config.Compatibility)
+	assert.Equal(t, "frames", config.CompatibilityGroup)
+}
+
+func TestMemoryRegistry_Mode(t *testing.T) {
+	t.Parallel()
+	registry := schemaregistry.NewMemoryRegistry()
+	_, err := registry.RegisterSubjectSchema("frames-value", testSchema)
+	require.Nil(t, err)
+
+	_, err = registry.SubjectMode("frames-value")
+	assertAPIError(t, schemaregistry.SubjectLevelModeNotConfigured, err)
+	_, err = registry.SetSubjectMode("frames-value", schemaregistry.ReadOnly)
+	require.Nil(t, err)
+	_, err = registry.RegisterSubjectSchema("frames-value", memoryTestSchema2)
+	_, ok := err.(*schemaregistry.ModeError)
+	assert.True(t, ok, "%v", err)
+	_, err = registry.DeleteSubject("frames-value")
+	assertAPIError(t, schemaregistry.OperationNotPermitted, err)
+	mode, err := registry.DeleteSubjectMode("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.ReadOnly, mode)
+
+	// explicit ids and versions are only accepted in import mode
+	_, err = registry.RegisterSubjectSchema("orders-value", memoryTestSchema2, schemaregistry.WithID(100))
+	assertAPIError(t, schemaregistry.OperationNotPermitted, err)
+	_, err = registry.SetMode(schemaregistry.Import)
+	assertAPIError(t, schemaregistry.OperationNotPermitted, err)
+	mode, err = registry.SetMode(schemaregistry.Import, schemaregistry.Force())
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Import, mode)
+
+	id, err := registry.RegisterSubjectSchema("orders-value", memoryTestSchema2, schemaregistry.WithID(100),
+		schemaregistry.WithVersion(5))
+	require.Nil(t, err)
+	assert.Equal(t, 100, id)
+	ss, err := registry.SubjectVersion("orders-value", 5)
+	require.Nil(t, err)
+	assert.Equal(t, 100, ss.ID)
+	_, err = registry.RegisterSubjectSchema("orders-value", testSchema, schemaregistry.WithID(100))
+	assertAPIError(t, schemaregistry.OperationNotPermitted, err)
+	_, err = registry.RegisterSubjectSchema("frames-value", testSchema, schemaregistry.WithID(101))
+	assertAPIError(t, schemaregistry.OperationNotPermitted, err)
+
+	_, err = registry.SetMode(schemaregistry.ReadWrite)
+	require.Nil(t, err)
+	// new ids follow the imported ones
+	id, err = registry.RegisterSubjectSchema("other-value", `{"type": "string"}`)
+	require.Nil(t, err)
+	assert.Equal(t, 101, id)
+}
+
+func TestMemoryRegistry_Concurrent(t *testing.T) {
+	t.Parallel()
+	registry := schemaregistry.NewMemoryRegistry()
+
+	var wg sync.WaitGroup
+	ids := make([]int, 20)
+	for i := range ids {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			schema := fmt.Sprintf(`{"type": "fixed", "name": "F%d", "size": 1}`, i%5)
+			id, err := registry.RegisterSubjectSchema(fmt.Sprintf("subject-%d", i%2), schema)
+			assert.Nil(t, err)
+			ids[i] = id
+			_, err = registry.SubjectVersions(fmt.Sprintf("subject-%d", i%2))
+			assert.Nil(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	// identical schemas share the id
+	for i := range ids {
+		assert.Equal(t, ids[i%5], ids[i])
+	}
+	for _, subject := range []string{"subject-0", "subject-1"} {
+		versions, err := registry.SubjectVersions(subject)
+		require.Nil(t, err)
+		assert.Len(t, versions, 5)
+	}
+}
+
+func TestMemoryRegistry_LargeNumbers(t *testing.T) {
+	t.Parallel()
+	registry := schemaregistry.NewMemoryRegistry()
+
+	// the defaults are the same float64, but different longs
+	id1, err := registry.RegisterSubjectSchema("a-value",
+		`{"type": "record", "name": "Frame", "fields": [{"name": "id", "type": "long", "default": 9007199254740993}]}`)
+	require.Nil(t, err)
+	id2, err := registry.RegisterSubjectSchema("b-value",
+		`{"type": "record", "name": "Frame", "fields": [{"name": "id", "type": "long", "default": 9007199254740992}]}`)
+	require.Nil(t, err)
+	assert.Equal(t, 1, id1)
+	assert.Equal(t, 2, id2)
+}
@@ -10,20 +10,24 @@ type MockRegistry struct {
 	mock.Mock
 }
 
-func (_m *MockRegistry) CheckSubjectSchema(subject string, schema string) (*SubjectSchema, error) {
-	ret := _m.Called(subject, schema)
+func (_m *MockRegistry) CheckSubjectSchema(subject string, schema string, opts ...SchemaOption) (*SubjectSchema, error) {
+	_ca := []interface{}{subject, schema}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
 
 	var r0 *SubjectSchema
 
-	if r0f, ok := ret.Get(0).(func(string, string) *SubjectSchema); ok {
-		r0 = r0f(subject, schema)
+	if r0f, ok := ret.Get(0).(func(string, string, ...SchemaOption) *SubjectSchema); ok {
+		r0 = r0f(subject, schema, opts...)
 	} else {
 		r0 = ret.Get(0).(*SubjectSchema)
 	}
 	var r1 error
 
-	if r1f, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = r1f(subject, schema)
+	if r1f, ok := ret.Get(1).(func(string, string, ...SchemaOption) error); ok {
+		r1 = r1f(subject, schema, opts...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -52,20 +56,158 @@ func (_m *MockRegistry) Config() (*Config, error) {
 	return r0, r1
 }
 
-func (_m *MockRegistry) RegisterSubjectSchema(subject string, schema string) (int, error) {
-	ret := _m.Called(subject, schema)
+func (_m *MockRegistry) DeleteSubject(subject string, opts ...DeleteOption) ([]int, error) {
+	_ca := []interface{}{subject}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
+
+	var r0 []int
+
+	if r0f, ok := ret.Get(0).(func(string, ...DeleteOption) []int); ok {
+		r0 = r0f(subject, opts...)
+	} else {
+		r0 = ret.Get(0).([]int)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(string, ...DeleteOption) error); ok {
+		r1 = r1f(subject, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockRegistry) DeleteSubjectConfig(subject string) (*Config, error) {
+	ret := _m.Called(subject)
+
+	var r0 *Config
+
+	if r0f, ok := ret.Get(0).(func(string) *Config); ok {
+		r0 = r0f(subject)
+	} else {
+		r0 = ret.Get(0).(*Config)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(string) error); ok {
+		r1 = r1f(subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockRegistry) DeleteSubjectMode(subject string) (Mode, error) {
+	ret := _m.Called(subject)
+
+	var r0 Mode
+
+	if r0f, ok := ret.Get(0).(func(string) Mode); ok {
+		r0 = r0f(subject)
+	} else {
+		r0 = ret.Get(0).(Mode)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(string) error); ok {
+		r1 = r1f(subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockRegistry) DeleteSubjectVersion(subject string, version int, opts ...DeleteOption) (int, error) {
+	_ca := []interface{}{subject, version}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
 
 	var r0 int
 
-	if r0f, ok := ret.Get(0).(func(string, string) int); ok {
-		r0 = r0f(subject, schema)
+	if r0f, ok := ret.Get(0).(func(string, int, ...DeleteOption) int); ok {
+		r0 = r0f(subject, version, opts...)
 	} else {
 		r0 = ret.Get(0).(int)
 	}
 	var r1 error
 
-	if r1f, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = r1f(subject, schema)
+	if r1f, ok := ret.Get(1).(func(string, int, ...DeleteOption) error); ok {
+		r1 = r1f(subject, version, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockRegistry) Mode() (Mode, error) {
+	ret := _m.Called()
+
+	var r0 Mode
+
+	if r0f, ok := ret.Get(0).(func() Mode); ok {
+		r0 = r0f()
+	} else {
+		r0 = ret.Get(0).(Mode)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func() error); ok {
+		r1 = r1f()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockRegistry) ReferencedBy(subject string, version int) ([]int, error) {
+	ret := _m.Called(subject, version)
+
+	var r0 []int
+
+	if r0f, ok := ret.Get(0).(func(string, int) []int); ok {
+		r0 = r0f(subject, version)
+	} else {
+		r0 = ret.Get(0).([]int)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = r1f(subject, version)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockRegistry) RegisterSubjectSchema(subject string, schema string, opts ...SchemaOption) (int, error) {
+	_ca := []interface{}{subject, schema}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
+
+	var r0 int
+
+	if r0f, ok := ret.Get(0).(func(string, string, ...SchemaOption) int); ok {
+		r0 = r0f(subject, schema, opts...)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(string, string, ...SchemaOption) error); ok {
+		r1 = r1f(subject, schema, opts...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -94,6 +236,48 @@ func (_m *MockRegistry) Schema(id int) (string, error) {
 	return r0, r1
 }
 
+func (_m *MockRegistry) SchemaByID(id int) (*SubjectSchema, error) {
+	ret := _m.Called(id)
+
+	var r0 *SubjectSchema
+
+	if r0f, ok := ret.Get(0).(func(int) *SubjectSchema); ok {
+		r0 = r0f(id)
+	} else {
+		r0 = ret.Get(0).(*SubjectSchema)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(int) error); ok {
+		r1 = r1f(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockRegistry) SchemaTypes() ([]SchemaType, error) {
+	ret := _m.Called()
+
+	var r0 []SchemaType
+
+	if r0f, ok := ret.Get(0).(func() []SchemaType); ok {
+		r0 = r0f()
+	} else {
+		r0 = ret.Get(0).([]SchemaType)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func() error); ok {
+		r1 = r1f()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (_m *MockRegistry) SetConfig(config *Config) (*Config, error) {
 	ret := _m.Called(config)
 
@@ -115,6 +299,31 @@ func (_m *MockRegistry) SetConfig(config *Config) (*Config, error) {
 	return r0, r1
 }
 
+func (_m *MockRegistry) SetMode(mode Mode, opts ...ModeOption) (Mode, error) {
+	_ca := []interface{}{mode}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
+
+	var r0 Mode
+
+	if r0f, ok := ret.Get(0).(func(Mode, ...ModeOption) Mode); ok {
+		r0 = r0f(mode, opts...)
+	} else {
+		r0 = ret.Get(0).(Mode)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(Mode, ...ModeOption) error); ok {
+		r1 = r1f(mode, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (_m *MockRegistry) SetSubjectConfig(subject string, config *Config) (*Config, error) {
 	ret := _m.Called(subject, config)
 
@@ -136,6 +345,31 @@ func (_m *MockRegistry) SetSubjectConfig(subject string, config *Config) (*Confi
 	return r0, r1
 }
 
+func (_m *MockRegistry) SetSubjectMode(subject string, mode Mode, opts ...ModeOption) (Mode, error) {
+	_ca := []interface{}{subject, mode}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
+
+	var r0 Mode
+
+	if r0f, ok := ret.Get(0).(func(string, Mode, ...ModeOption) Mode); ok {
+		r0 = r0f(subject, mode, opts...)
+	} else {
+		r0 = ret.Get(0).(Mode)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(string, Mode, ...ModeOption) error); ok {
+		r1 = r1f(subject, mode, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 func (_m *MockRegistry) SubjectConfig(subject string) (*Config, error) {
 	ret := _m.Called(subject)
 
@@ -157,15 +391,36 @@ func (_m *MockRegistry) SubjectConfig(subject string) (*Config, error) {
 	return r0, r1
 }
 
-func (_m *MockRegistry) SubjectVersion(subject string, version int) (string, error) {
+func (_m *MockRegistry) SubjectMode(subject string) (Mode, error) {
+	ret := _m.Called(subject)
+
+	var r0 Mode
+
+	if r0f, ok := ret.Get(0).(func(string) Mode); ok {
+		r0 = r0f(subject)
+	} else {
+		r0 = ret.Get(0).(Mode)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(string) error); ok {
+		r1 = r1f(subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockRegistry) SubjectVersion(subject string, version int) (*SubjectSchema, error) {
 	ret := _m.Called(subject, version)
 
-	var r0 string
+	var r0 *SubjectSchema
 
-	if r0f, ok := ret.Get(0).(func(string, int) string); ok {
+	if r0f, ok := ret.Get(0).(func(string, int) *SubjectSchema); ok {
 		r0 = r0f(subject, version)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(*SubjectSchema)
 	}
 	var r1 error
 
@@ -178,20 +433,24 @@ func (_m *MockRegistry) SubjectVersion(subject string, version int) (string, err
 	return r0, r1
 }
 
-func (_m *MockRegistry) SubjectVersions(subject string) ([]int, error) {
-	ret := _m.Called(subject)
+func (_m *MockRegistry) SubjectVersions(subject string, opts ...ListOption) ([]int, error) {
+	_ca := []interface{}{subject}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
 
 	var r0 []int
 
-	if r0f, ok := ret.Get(0).(func(string) []int); ok {
-		r0 = r0f(subject)
+	if r0f, ok := ret.Get(0).(func(string, ...ListOption) []int); ok {
+		r0 = r0f(subject, opts...)
 	} else {
 		r0 = ret.Get(0).([]int)
 	}
 	var r1 error
 
-	if r1f, ok := ret.Get(1).(func(string) error); ok {
-		r1 = r1f(subject)
+	if r1f, ok := ret.Get(1).(func(string, ...ListOption) error); ok {
+		r1 = r1f(subject, opts...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -199,20 +458,24 @@ func (_m *MockRegistry) SubjectVersions(subject string) ([]int, error) {
 	return r0, r1
 }
 
-func (_m *MockRegistry) Subjects() ([]string, error) {
-	ret := _m.Called()
+func (_m *MockRegistry) Subjects(opts ...ListOption) ([]string, error) {
+	var _ca []interface{}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
 
 	var r0 []string
 
-	if r0f, ok := ret.Get(0).(func() []string); ok {
-		r0 = r0f()
+	if r0f, ok := ret.Get(0).(func(...ListOption) []string); ok {
+		r0 = r0f(opts...)
 	} else {
 		r0 = ret.Get(0).([]string)
 	}
 	var r1 error
 
-	if r1f, ok := ret.Get(1).(func() error); ok {
-		r1 = r1f()
+	if r1f, ok := ret.Get(1).(func(...ListOption) error); ok {
+		r1 = r1f(opts...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -220,20 +483,49 @@ func (_m *MockRegistry) Subjects() ([]string, error) {
 	return r0, r1
 }
 
-func (_m *MockRegistry) TestCompatibility(subject string, version int, schema string) (bool, error) {
-	ret := _m.Called(subject, version, schema)
+func (_m *MockRegistry) TestCompatibility(subject string, version int, schema string, opts ...SchemaOption) (bool, error) {
+	_ca := []interface{}{subject, version, schema}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
 
 	var r0 bool
 
-	if r0f, ok := ret.Get(0).(func(string, int, string) bool); ok {
-		r0 = r0f(subject, version, schema)
+	if r0f, ok := ret.Get(0).(func(string, int, string, ...SchemaOption) bool); ok {
+		r0 = r0f(subject, version, schema, opts...)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
 	var r1 error
 
-	if r1f, ok := ret.Get(1).(func(string, int, string) error); ok {
-		r1 = r1f(subject, version, schema)
+	if r1f, ok := ret.Get(1).(func(string, int, string, ...SchemaOption) error); ok {
+		r1 = r1f(subject, version, schema, opts...)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+func (_m *MockRegistry) TestCompatibilityAll(subject string, schema string, opts ...SchemaOption) (*CompatibilityResult, error) {
+	_ca := []interface{}{subject, schema}
+	for _, opt := range opts {
+		_ca = append(_ca, opt)
+	}
+	ret := _m.Called(_ca...)
+
+	var r0 *CompatibilityResult
+
+	if r0f, ok := ret.Get(0).(func(string, string, ...SchemaOption) *CompatibilityResult); ok {
+		r0 = r0f(subject, schema, opts...)
+	} else {
+		r0 = ret.Get(0).(*CompatibilityResult)
+	}
+	var r1 error
+
+	if r1f, ok := ret.Get(1).(func(string, string, ...SchemaOption) error); ok {
+		r1 = r1f(subject, schema, opts...)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -0,0 +1,107 @@
+package schemaregistry
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Mode is the mode of the registry (global or of a subject), restricting the operations allowed.
+//
+//go:generate stringer -type=Mode
+type Mode int
+
+const (
+	// ReadWrite allows all the operations (default).
+	ReadWrite Mode = iota
+
+	// ReadOnly rejects the operations that modify the registry, like registering schemas.
+	ReadOnly
+
+	// ReadOnlyOverride is like ReadOnly, but it also applies to the subjects with a mode of their own.
+	ReadOnlyOverride
+
+	// Import allows registering schemas with explicit ids and versions (WithID and WithVersion), to migrate schemas
+	// from another registry.
+	Import
+)
+
+// modeNames are the names used by the registry for each Mode.
+var modeNames = [...]string{
+	ReadWrite:        "READWRITE",
+	ReadOnly:         "READONLY",
+	ReadOnlyOverride: "READONLY_OVERRIDE",
+	Import:           "IMPORT",
+}
+
+// MarshalText encodes the mode as the name used by the registry (READWRITE, READONLY, IMPORT, etc.).
+func (m Mode) MarshalText() ([]byte, error) {
+	if m < 0 || int(m) >= len(modeNames) {
+		return nil, errors.Errorf("invalid mode: %d", m)
+	}
+	return []byte(modeNames[m]), nil
+}
+
+// UnmarshalText decodes a mode name used by the registry (READWRITE, READONLY, IMPORT, etc.).
+func (m *Mode) UnmarshalText(text []byte) error {
+	name := strings.ToUpper(string(text))
+	for i, modeName := range modeNames {
+		if modeName == name {
+			*m = Mode(i)
+			return nil
+		}
+	}
+	return errors.Errorf("invalid mode: %s", text)
+}
+
+type modeJSON struct {
+	Mode Mode `json:"mode"`
+}
+
+// ModeOption configures the operations SetMode and SetSubjectMode of Registry.
+type ModeOption func(*modeOptions)
+
+type modeOptions struct {
+	force bool
+}
+
+func (o *modeOptions) query() url.Values {
+	q := url.Values{}
+	if o.force {
+		q.Set("force", "true")
+	}
+	return q
+}
+
+func newModeOptions(opts []ModeOption) *modeOptions {
+	o := &modeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Force makes the registry change the mode even if it would refuse otherwise, like when setting Import in a
+// registry (or subject) that already has schemas.
+func Force() ModeOption {
+	return func(o *modeOptions) {
+		o.force = true
+	}
+}
+
+// ModeError is the error of the operations rejected by the mode of the registry or of the subject, like registering
+// a schema in ReadOnly mode. It wraps the *APIError, with code OperationNotPermitted.
+type ModeError struct {
+	*APIError
+}
+
+func (e *ModeError) Error() string {
+	return fmt.Sprintf("operation not permitted by the registry mode: %s", e.APIError.Error())
+}
+
+// Unwrap returns the *APIError.
+func (e *ModeError) Unwrap() error {
+	return e.APIError
+}
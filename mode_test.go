@@ -0,0 +1,132 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMode_Text(t *testing.T) {
+	t.Parallel()
+	modes := map[schemaregistry.Mode]string{
+		schemaregistry.ReadWrite:        "READWRITE",
+		schemaregistry.ReadOnly:         "READONLY",
+		schemaregistry.ReadOnlyOverride: "READONLY_OVERRIDE",
+		schemaregistry.Import:           "IMPORT",
+	}
+	for m, name := range modes {
+		text, err := m.MarshalText()
+		require.Nil(t, err)
+		assert.Equal(t, name, string(text))
+
+		var decoded schemaregistry.Mode
+		require.Nil(t, decoded.UnmarshalText([]byte(name)))
+		assert.Equal(t, m, decoded)
+	}
+
+	var m schemaregistry.Mode
+	assert.Error(t, m.UnmarshalText([]byte("WRITEONLY")))
+	_, err := schemaregistry.Mode(42).MarshalText()
+	assert.Error(t, err)
+	assert.Equal(t, "Import", schemaregistry.Import.String())
+}
+
+func TestRegistry_ModeOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.String() {
+		case "GET /mode":
+			w.Write([]byte(`{"mode":"READWRITE"}`))
+		case "PUT /mode?force=true", "PUT /mode/frames-value":
+			var msg map[string]string
+			require.Nil(t, json.NewDecoder(r.Body).Decode(&msg))
+			assert.Equal(t, map[string]string{"mode": "IMPORT"}, msg)
+			w.Write([]byte(`{"mode":"IMPORT"}`))
+		case "GET /mode/frames-value", "DELETE /mode/frames-value":
+			w.Write([]byte(`{"mode":"READONLY"}`))
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		}
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	mode, err := registry.Mode()
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.ReadWrite, mode)
+
+	mode, err = registry.SetMode(schemaregistry.Import, schemaregistry.Force())
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Import, mode)
+
+	mode, err = registry.SetSubjectMode("frames-value", schemaregistry.Import)
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Import, mode)
+
+	mode, err = registry.SubjectMode("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.ReadOnly, mode)
+
+	mode, err = registry.DeleteSubjectMode("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.ReadOnly, mode)
+}
+
+func TestRegistry_RegisterSubjectSchemaImport(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects/frames-value/versions", r.URL.String())
+
+		var msg map[string]interface{}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&msg))
+		assert.Equal(t, map[string]interface{}{"schema": testSchema, "id": float64(100), "version": float64(3)}, msg)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 100})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	id, err := registry.RegisterSubjectSchema("frames-value", testSchema, schemaregistry.WithID(100),
+		schemaregistry.WithVersion(3))
+	require.Nil(t, err)
+	assert.Equal(t, 100, id)
+}
+
+func TestRegistry_ErrOperationNotPermitted(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.OperationNotPermitted,
+			Message: "Subject frames-value is in read-only mode",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.RegisterSubjectSchema("frames-value", testSchema)
+	modeErr, ok := err.(*schemaregistry.ModeError)
+	require.True(t, ok, "%v", err)
+	assert.Equal(t, schemaregistry.OperationNotPermitted, modeErr.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, modeErr.StatusCode)
+	assert.EqualError(t, err, "operation not permitted by the registry mode: Schema Registry API error, code: 42205 "+
+		"message: Subject frames-value is in read-only mode")
+
+	_, err = registry.SetMode(schemaregistry.Import)
+	var apiErr *schemaregistry.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, schemaregistry.OperationNotPermitted, apiErr.Code)
+	assert.False(t, schemaregistry.IsRetryable(err))
+}
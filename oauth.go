@@ -0,0 +1,216 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultOAuthExpiryMargin is the default time before the expiration of an OAuth token when it's refreshed.
+const DefaultOAuthExpiryMargin = 30 * time.Second
+
+// DefaultOAuthTimeout is the default timeout of the requests to the token endpoint.
+const DefaultOAuthTimeout = 30 * time.Second
+
+// maxTokenResponse is the max number of bytes read from a response of the token endpoint.
+const maxTokenResponse = 1 << 20
+
+// OAuthConfig configures an OAuthTokenProvider. The fields mirror the bearer.auth.* settings of the Confluent
+// clients.
+type OAuthConfig struct {
+	// TokenURL is the token endpoint of the authorization server.
+	TokenURL string
+
+	// ClientID and ClientSecret are the client credentials, sent with HTTP Basic auth to the token endpoint.
+	ClientID     string
+	ClientSecret string
+
+	// Scopes are the requested scopes, if any.
+	Scopes []string
+
+	// LogicalCluster is the logical cluster of the registry, sent in the target-sr-cluster header, if not empty.
+	LogicalCluster string
+
+	// IdentityPoolID is the identity pool, sent in the Confluent-Identity-Pool-Id header, if not empty.
+	IdentityPoolID string
+
+	// ExpiryMargin is the time before the expiration of a token when it's refreshed. Zero means
+	// DefaultOAuthExpiryMargin.
+	ExpiryMargin time.Duration
+
+	// Timeout bounds each request to the token endpoint, which is shared by the callers waiting for the token. Zero
+	// means DefaultOAuthTimeout.
+	Timeout time.Duration
+
+	// HTTPClient is the client used to get tokens. Nil means http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// OAuthTokenProvider is a CredentialsProvider of bearer tokens, got from an OAuth2 token endpoint with the client
+// credentials flow. Tokens are cached until shortly before they expire, and concurrent refreshes share a single
+// request to the token endpoint. It's also a CredentialsInvalidator, so tokens rejected by the registry are
+// refreshed.
+type OAuthTokenProvider struct {
+	config OAuthConfig
+	client *http.Client
+	header http.Header
+
+	mu          sync.Mutex
+	credentials *Credentials
+	expiresAt   time.Time // zero if the token doesn't expire
+
+	flights flightGroup
+}
+
+// NewOAuthTokenProvider returns an OAuthTokenProvider. Use it with WithCredentialsProvider.
+func NewOAuthTokenProvider(config OAuthConfig) (*OAuthTokenProvider, error) {
+	_, err := url.ParseRequestURI(config.TokenURL)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid token URL: %s", config.TokenURL)
+	}
+	if config.ClientID == "" {
+		return nil, errors.New("missing OAuth client id")
+	}
+	if config.ExpiryMargin == 0 {
+		config.ExpiryMargin = DefaultOAuthExpiryMargin
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultOAuthTimeout
+	}
+
+	p := &OAuthTokenProvider{
+		config: config,
+		client: config.HTTPClient,
+		header: http.Header{},
+	}
+	if p.client == nil {
+		p.client = http.DefaultClient
+	}
+	if config.LogicalCluster != "" {
+		p.header.Set("target-sr-cluster", config.LogicalCluster)
+	}
+	if config.IdentityPoolID != "" {
+		p.header.Set("Confluent-Identity-Pool-Id", config.IdentityPoolID)
+	}
+	return p, nil
+}
+
+// Credentials returns the cached token, fetching a new one if there is none or it's about to expire.
+func (p *OAuthTokenProvider) Credentials(ctx context.Context) (*Credentials, error) {
+	p.mu.Lock()
+	credentials := p.credentials
+	fresh := credentials != nil && (p.expiresAt.IsZero() || time.Now().Before(p.expiresAt))
+	p.mu.Unlock()
+	if fresh {
+		return credentials, nil
+	}
+
+	// the fetch is shared by the concurrent callers, so it isn't cancelled until all of them give up, and it's
+	// bounded by its own timeout
+	fetched, err := p.flights.do(ctx, "token", func(ctx context.Context) (interface{}, error) {
+		return p.fetch(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fetched.(*Credentials), nil
+}
+
+// InvalidateCredentials discards the cached token, if it's credentials, so the next call to Credentials fetches a
+// new one.
+func (p *OAuthTokenProvider) InvalidateCredentials(credentials *Credentials) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.credentials == credentials {
+		p.credentials = nil
+	}
+}
+
+type oauthTokenJSON struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+type oauthErrorJSON struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fetch gets a new token from the token endpoint and caches it.
+func (p *OAuthTokenProvider) fetch(ctx context.Context) (*Credentials, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(p.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(p.config.Scopes, " "))
+	}
+	req, err := http.NewRequest(http.MethodPost, p.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating request POST %s", p.config.TokenURL)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	ctx, cancel := context.WithTimeout(ctx, p.config.Timeout)
+	defer cancel()
+	requestedAt := time.Now()
+	resp, err := p.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error in POST %s", p.config.TokenURL)
+	}
+	defer closeBody(resp.Body)
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponse))
+	if err != nil {
+		return nil, errors.Wrapf(err, "error reading response of POST %s", p.config.TokenURL)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var errMsg oauthErrorJSON
+		if json.Unmarshal(data, &errMsg) == nil && errMsg.Error != "" {
+			return nil, errors.Errorf("error getting OAuth token, status=%d: %s %s", resp.StatusCode, errMsg.Error,
+				errMsg.ErrorDescription)
+		}
+		return nil, errors.Errorf("error getting OAuth token, status=%d", resp.StatusCode)
+	}
+
+	var token oauthTokenJSON
+	err = json.Unmarshal(data, &token)
+	if err != nil {
+		return nil, errors.Wrap(err, "error decoding OAuth token")
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("error getting OAuth token: empty access_token")
+	}
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return nil, errors.Errorf("error getting OAuth token: unsupported token_type %s", token.TokenType)
+	}
+
+	credentials := &Credentials{
+		BearerToken: token.AccessToken,
+		Header:      p.header,
+	}
+	var expiresAt time.Time
+	if token.ExpiresIn > 0 {
+		// the margin is capped to half the lifetime, so short-lived tokens are still cached
+		lifetime := time.Duration(token.ExpiresIn) * time.Second
+		margin := p.config.ExpiryMargin
+		if margin > lifetime/2 {
+			margin = lifetime / 2
+		}
+		expiresAt = requestedAt.Add(lifetime - margin)
+	}
+
+	p.mu.Lock()
+	p.credentials = credentials
+	p.expiresAt = expiresAt
+	p.mu.Unlock()
+	return credentials, nil
+}
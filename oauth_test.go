@@ -0,0 +1,256 @@
+package schemaregistry_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tokenServer returns an OAuth token server issuing "token-N" tokens, with the expiration given by expiresIn.
+func tokenServer(t *testing.T, issued *int32, expiresIn int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		require.Nil(t, r.ParseForm())
+		assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		assert.Equal(t, "registry.read registry.write", r.PostForm.Get("scope"))
+		clientID, clientSecret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "frames-service", clientID)
+		assert.Equal(t, "s3cr3t", clientSecret)
+
+		// slow enough for concurrent fetches to overlap
+		time.Sleep(20 * time.Millisecond)
+		n := atomic.AddInt32(issued, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+}
+
+func newTestOAuthProvider(t *testing.T, tokenURL string) *schemaregistry.OAuthTokenProvider {
+	provider, err := schemaregistry.NewOAuthTokenProvider(schemaregistry.OAuthConfig{
+		TokenURL:       tokenURL,
+		ClientID:       "frames-service",
+		ClientSecret:   "s3cr3t",
+		Scopes:         []string{"registry.read", "registry.write"},
+		LogicalCluster: "lsrc-123",
+		IdentityPoolID: "pool-abc",
+	})
+	require.Nil(t, err)
+	return provider
+}
+
+func TestOAuthTokenProvider_CachedToken(t *testing.T) {
+	t.Parallel()
+	var issued int32
+	tokens := tokenServer(t, &issued, 3600)
+	defer tokens.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token-1", r.Header.Get("Authorization"))
+		assert.Equal(t, "lsrc-123", r.Header.Get("target-sr-cluster"))
+		assert.Equal(t, "pool-abc", r.Header.Get("Confluent-Identity-Pool-Id"))
+		json.NewEncoder(w).Encode([]string{"frames-value"})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL,
+		schemaregistry.WithCredentialsProvider(newTestOAuthProvider(t, tokens.URL)))
+	require.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = registry.Subjects()
+		require.Nil(t, err)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&issued))
+}
+
+func TestOAuthTokenProvider_ConcurrentRefresh(t *testing.T) {
+	t.Parallel()
+	var issued int32
+	tokens := tokenServer(t, &issued, 3600)
+	defer tokens.Close()
+	provider := newTestOAuthProvider(t, tokens.URL)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			credentials, err := provider.Credentials(context.Background())
+			if assert.Nil(t, err) {
+				assert.Equal(t, "token-1", credentials.BearerToken)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, atomic.LoadInt32(&issued))
+}
+
+func TestOAuthTokenProvider_FirstCallerCancelled(t *testing.T) {
+	t.Parallel()
+	var issued int32
+	release := make(chan struct{})
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		atomic.AddInt32(&issued, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token-1", "token_type": "Bearer"})
+	}))
+	defer tokens.Close()
+	provider := newTestOAuthProvider(t, tokens.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := provider.Credentials(ctx)
+		first <- err
+	}()
+	for schemaregistry.FlightWaiters(provider) < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	second := make(chan *schemaregistry.Credentials)
+	go func() {
+		credentials, err := provider.Credentials(context.Background())
+		assert.Nil(t, err)
+		second <- credentials
+	}()
+	for schemaregistry.FlightWaiters(provider) < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// the first caller gives up while the token is being fetched, the other one still gets it
+	cancel()
+	assert.Equal(t, context.Canceled, <-first)
+	close(release)
+	credentials := <-second
+	if assert.NotNil(t, credentials) {
+		assert.Equal(t, "token-1", credentials.BearerToken)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&issued))
+}
+
+func TestOAuthTokenProvider_RefreshBeforeExpiry(t *testing.T) {
+	t.Parallel()
+	var issued int32
+	// with a 1s lifetime, the (capped) margin makes the token expire after 500ms
+	tokens := tokenServer(t, &issued, 1)
+	defer tokens.Close()
+	provider := newTestOAuthProvider(t, tokens.URL)
+
+	credentials, err := provider.Credentials(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, "token-1", credentials.BearerToken)
+
+	time.Sleep(600 * time.Millisecond)
+	credentials, err = provider.Credentials(context.Background())
+	require.Nil(t, err)
+	assert.Equal(t, "token-2", credentials.BearerToken)
+}
+
+func TestOAuthTokenProvider_RetryOnUnauthorized(t *testing.T) {
+	t.Parallel()
+	var issued int32
+	tokens := tokenServer(t, &issued, 3600)
+	defer tokens.Close()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.Header.Get("Authorization") != "Bearer token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(&schemaregistry.APIError{Code: 40101, Message: "Unauthorized"})
+			return
+		}
+		json.NewEncoder(w).Encode([]string{"frames-value"})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL,
+		schemaregistry.WithCredentialsProvider(newTestOAuthProvider(t, tokens.URL)))
+	require.Nil(t, err)
+
+	subjects, err := registry.Subjects()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"frames-value"}, subjects)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&issued))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestOAuthTokenProvider_UnauthorizedRetriedOnce(t *testing.T) {
+	t.Parallel()
+	var issued int32
+	tokens := tokenServer(t, &issued, 3600)
+	defer tokens.Close()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{Code: 40101, Message: "Unauthorized"})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL,
+		schemaregistry.WithCredentialsProvider(newTestOAuthProvider(t, tokens.URL)))
+	require.Nil(t, err)
+
+	_, err = registry.Subjects()
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestOAuthTokenProvider_TokenError(t *testing.T) {
+	t.Parallel()
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":             "invalid_client",
+			"error_description": "bad client credentials",
+		})
+	}))
+	defer tokens.Close()
+	provider := newTestOAuthProvider(t, tokens.URL)
+
+	_, err := provider.Credentials(context.Background())
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid_client")
+		assert.NotContains(t, err.Error(), "s3cr3t")
+	}
+}
+
+func TestOAuthTokenProvider_Timeout(t *testing.T) {
+	t.Parallel()
+	hung := make(chan struct{})
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-hung:
+		case <-r.Context().Done():
+		}
+	}))
+	defer tokens.Close()
+	defer close(hung)
+	provider, err := schemaregistry.NewOAuthTokenProvider(schemaregistry.OAuthConfig{
+		TokenURL: tokens.URL,
+		ClientID: "frames-service",
+		Timeout:  50 * time.Millisecond,
+	})
+	require.Nil(t, err)
+
+	// callers without deadline don't wait for a hung token endpoint forever
+	start := time.Now()
+	_, err = provider.Credentials(context.Background())
+	assert.True(t, errors.Is(err, context.DeadlineExceeded), "%v", err)
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
@@ -0,0 +1,101 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_AcceptHeader(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, strings.HasPrefix(r.Header.Get("Accept"), "application/vnd.schemaregistry.v1+json"))
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 1})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.RegisterSubjectSchema("frames-value", testSchema)
+	require.Nil(t, err)
+}
+
+func TestRegistry_ErrNonJSONResponse(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte("<html><body>502 Bad Gateway</body></html>"))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.CheckSubjectSchema("frames-value", testSchema)
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.EqualValues(t, http.StatusBadGateway, apiErr.Code)
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, "Bad Gateway", apiErr.Message)
+	assert.Equal(t, "<html><body>502 Bad Gateway</body></html>", apiErr.Body)
+	assert.Contains(t, apiErr.Error(), "502 Bad Gateway")
+}
+
+func TestRegistry_ErrNonJSONResponseSnippet(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(strings.Repeat("x", 10000)))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.Schema(1)
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.True(t, len(apiErr.Body) > 0 && len(apiErr.Body) < 10000)
+}
+
+func TestRegistry_ReusesConnections(t *testing.T) {
+	t.Parallel()
+	var conns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.SubjectNotFound,
+			Message: "Subject not found",
+		})
+	}))
+	ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithHTTPClient(&http.Client{}))
+	require.Nil(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err = registry.RegisterSubjectSchema("frames-value", testSchema)
+		apiErr, ok := err.(*schemaregistry.APIError)
+		require.True(t, ok)
+		assert.Equal(t, schemaregistry.SubjectNotFound, apiErr.Code)
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&conns))
+}
@@ -0,0 +1,79 @@
+package protobuf
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// ErrInvalidMessageIndexes is returned when the message indexes after the wire format header can't be decoded.
+var ErrInvalidMessageIndexes = errors.New("invalid Protobuf message indexes")
+
+// AppendMessageIndexes appends the message indexes to dst, in the Confluent Protobuf wire format: the number of
+// indexes and the indexes, as zigzag varints. The indexes [0] (the first message of the file, the most common case)
+// are encoded as a single 0.
+func AppendMessageIndexes(dst []byte, indexes []int) []byte {
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return append(dst, 0)
+	}
+	dst = binary.AppendVarint(dst, int64(len(indexes)))
+	for _, index := range indexes {
+		dst = binary.AppendVarint(dst, int64(index))
+	}
+	return dst
+}
+
+// ParseMessageIndexes parses the message indexes at the start of data, returning them and the rest of data (the
+// payload).
+func ParseMessageIndexes(data []byte) (indexes []int, payload []byte, err error) {
+	count, n := binary.Varint(data)
+	if n <= 0 || count < 0 || count > int64(len(data)) {
+		return nil, nil, ErrInvalidMessageIndexes
+	}
+	data = data[n:]
+	if count == 0 {
+		return []int{0}, data, nil
+	}
+	indexes = make([]int, count)
+	for i := range indexes {
+		index, n := binary.Varint(data)
+		if n <= 0 || index < 0 {
+			return nil, nil, ErrInvalidMessageIndexes
+		}
+		indexes[i] = int(index)
+		data = data[n:]
+	}
+	return indexes, data, nil
+}
+
+// messageIndexes returns the indexes of the message md in its file: the index of the top-level message, followed by
+// the indexes of the nested messages down to md.
+func messageIndexes(md protoreflect.MessageDescriptor) []int {
+	var indexes []int
+	for d := protoreflect.Descriptor(md); ; d = d.Parent() {
+		if _, ok := d.(protoreflect.MessageDescriptor); !ok {
+			break
+		}
+		indexes = append(indexes, d.Index())
+	}
+	// reverse, from the top-level message
+	for i, j := 0, len(indexes)-1; i < j; i, j = i+1, j-1 {
+		indexes[i], indexes[j] = indexes[j], indexes[i]
+	}
+	return indexes
+}
+
+// messageByIndexes returns the message of fd at indexes.
+func messageByIndexes(fd protoreflect.FileDescriptor, indexes []int) (protoreflect.MessageDescriptor, error) {
+	messages := fd.Messages()
+	var md protoreflect.MessageDescriptor
+	for _, index := range indexes {
+		if index >= messages.Len() {
+			return nil, errors.Errorf("message indexes %v not found in %s", indexes, fd.Path())
+		}
+		md = messages.Get(index)
+		messages = md.Messages()
+	}
+	return md, nil
+}
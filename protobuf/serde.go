@@ -0,0 +1,314 @@
+// Package protobuf implements Protobuf serializers and deserializers of Kafka messages in the Confluent wire format,
+// with the schemas managed by a schema registry.
+package protobuf
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoprint"
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Serializer encodes Protobuf messages in the Confluent Protobuf wire format: the wire format header, the indexes
+// of the message type in its .proto file, and the encoded message. It's safe for concurrent use.
+type Serializer struct {
+	registry     schemaregistry.Registry
+	autoRegister bool
+	nameStrategy schemaregistry.SubjectNameStrategy
+
+	// ids caches the ids of the schemas by subject and file path (subjectFile -> int)
+	ids sync.Map
+
+	// references caches the references to the imported files by path (string -> schemaregistry.SchemaReference)
+	references sync.Map
+}
+
+// SerializerOption configures a Serializer.
+type SerializerOption func(*Serializer)
+
+// AutoRegisterSchemas sets if the schemas (and the files they import) are registered (the default, like the
+// auto.register.schemas setting of the Confluent serializers). If disabled, they must be already registered.
+func AutoRegisterSchemas(enabled bool) SerializerOption {
+	return func(s *Serializer) {
+		s.autoRegister = enabled
+	}
+}
+
+// WithSubjectNameStrategy sets the strategy naming the subjects of the topics in SerializeKey and SerializeValue.
+// The default is schemaregistry.TopicNameStrategy.
+func WithSubjectNameStrategy(strategy schemaregistry.SubjectNameStrategy) SerializerOption {
+	return func(s *Serializer) {
+		s.nameStrategy = strategy
+	}
+}
+
+// NewSerializer returns a Serializer of the schemas of registry.
+func NewSerializer(registry schemaregistry.Registry, opts ...SerializerOption) *Serializer {
+	s := &Serializer{
+		registry:     registry,
+		autoRegister: true,
+		nameStrategy: schemaregistry.TopicNameStrategy,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type subjectFile struct {
+	subject string
+	path    string
+}
+
+// Serialize encodes msg, registering or looking up the .proto file of its type in subject, and returns the message
+// in the wire format. The files imported by the .proto file are registered too, in subjects named after their
+// import paths, and referenced by the schema. The well-known types (google/protobuf/*.proto) are known by the
+// registry, so they're never registered.
+func (s *Serializer) Serialize(subject string, msg proto.Message) ([]byte, error) {
+	md := msg.ProtoReflect().Descriptor()
+	id, err := s.schemaID(subject, md.ParentFile())
+	if err != nil {
+		return nil, err
+	}
+	payload, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error encoding Protobuf message %s", md.FullName())
+	}
+	message := make([]byte, 0, schemaregistry.WireHeaderSize+4+len(payload))
+	message = schemaregistry.AppendWireHeader(message, id)
+	message = AppendMessageIndexes(message, messageIndexes(md))
+	return append(message, payload...), nil
+}
+
+// SerializeKey is like Serialize, for a key of topic. The subject is named by the SubjectNameStrategy, with the full
+// name of the message type as record name.
+func (s *Serializer) SerializeKey(topic string, msg proto.Message) ([]byte, error) {
+	return s.serializeTopic(topic, true, msg)
+}
+
+// SerializeValue is like Serialize, for a value of topic. The subject is named by the SubjectNameStrategy, with the
+// full name of the message type as record name.
+func (s *Serializer) SerializeValue(topic string, msg proto.Message) ([]byte, error) {
+	return s.serializeTopic(topic, false, msg)
+}
+
+func (s *Serializer) serializeTopic(topic string, isKey bool, msg proto.Message) ([]byte, error) {
+	recordName := string(msg.ProtoReflect().Descriptor().FullName())
+	subject, err := s.nameStrategy.Subject(topic, isKey, recordName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error naming subject of topic %s", topic)
+	}
+	return s.Serialize(subject, msg)
+}
+
+// schemaID returns the id of the schema of fd in subject.
+func (s *Serializer) schemaID(subject string, fd protoreflect.FileDescriptor) (int, error) {
+	key := subjectFile{subject: subject, path: fd.Path()}
+	if id, ok := s.ids.Load(key); ok {
+		return id.(int), nil
+	}
+	schema, opts, err := s.schema(fd)
+	if err != nil {
+		return 0, err
+	}
+
+	var id int
+	if s.autoRegister {
+		id, err = s.registry.RegisterSubjectSchema(subject, schema, opts...)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		ss, err := s.registry.CheckSubjectSchema(subject, schema, opts...)
+		if err != nil {
+			return 0, err
+		}
+		id = ss.ID
+	}
+	s.ids.Store(key, id)
+	return id, nil
+}
+
+// schema returns the schema of fd, and the options to register it (its type and references), registering or
+// looking up the files it imports.
+func (s *Serializer) schema(fd protoreflect.FileDescriptor) (string, []schemaregistry.SchemaOption, error) {
+	var references []schemaregistry.SchemaReference
+	imports := fd.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		imported := imports.Get(i).FileDescriptor
+		if isWellKnown(imported.Path()) {
+			continue
+		}
+		reference, err := s.reference(imported)
+		if err != nil {
+			return "", nil, err
+		}
+		references = append(references, reference)
+	}
+
+	schema, err := printSchema(fd)
+	if err != nil {
+		return "", nil, err
+	}
+	opts := []schemaregistry.SchemaOption{schemaregistry.WithSchemaType(schemaregistry.Protobuf)}
+	if len(references) > 0 {
+		opts = append(opts, schemaregistry.WithReferences(references...))
+	}
+	return schema, opts, nil
+}
+
+// reference returns the reference to the imported file fd, registering it (if enabled) in the subject named after
+// its path.
+func (s *Serializer) reference(fd protoreflect.FileDescriptor) (schemaregistry.SchemaReference, error) {
+	if reference, ok := s.references.Load(fd.Path()); ok {
+		return reference.(schemaregistry.SchemaReference), nil
+	}
+	schema, opts, err := s.schema(fd)
+	if err != nil {
+		return schemaregistry.SchemaReference{}, err
+	}
+
+	subject := fd.Path()
+	if s.autoRegister {
+		_, err = s.registry.RegisterSubjectSchema(subject, schema, opts...)
+		if err != nil {
+			return schemaregistry.SchemaReference{}, errors.Wrapf(err, "error registering import %s", fd.Path())
+		}
+	}
+	// the version is only known by looking up the schema
+	ss, err := s.registry.CheckSubjectSchema(subject, schema, opts...)
+	if err != nil {
+		return schemaregistry.SchemaReference{}, errors.Wrapf(err, "error looking up import %s", fd.Path())
+	}
+	reference := schemaregistry.SchemaReference{Name: fd.Path(), Subject: subject, Version: ss.Version}
+	s.references.Store(fd.Path(), reference)
+	return reference, nil
+}
+
+// Deserializer decodes Protobuf messages in the wire format, with the writer schemas of the registry. It's safe for
+// concurrent use.
+type Deserializer struct {
+	registry schemaregistry.Registry
+
+	// files caches the compiled writer schemas by id (int -> protoreflect.FileDescriptor)
+	files sync.Map
+}
+
+// NewDeserializer returns a Deserializer of the schemas of registry.
+func NewDeserializer(registry schemaregistry.Registry) *Deserializer {
+	return &Deserializer{
+		registry: registry,
+	}
+}
+
+// Deserialize decodes message into msg, a generated (or dynamic) message of the type written. It fails if the type
+// of msg isn't the written one.
+func (d *Deserializer) Deserialize(message []byte, msg proto.Message) error {
+	md, payload, err := d.parse(message)
+	if err != nil {
+		return err
+	}
+	if target := msg.ProtoReflect().Descriptor().FullName(); target != md.FullName() {
+		return errors.Errorf("can't decode Protobuf message %s into %s", md.FullName(), target)
+	}
+	err = proto.Unmarshal(payload, msg)
+	if err != nil {
+		return errors.Wrapf(err, "error decoding Protobuf message %s", md.FullName())
+	}
+	return nil
+}
+
+// DeserializeDynamic decodes message into a dynamic message of the written type, described by the writer schema.
+func (d *Deserializer) DeserializeDynamic(message []byte) (*dynamicpb.Message, error) {
+	md, payload, err := d.parse(message)
+	if err != nil {
+		return nil, err
+	}
+	msg := dynamicpb.NewMessage(md)
+	err = proto.Unmarshal(payload, msg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error decoding Protobuf message %s", md.FullName())
+	}
+	return msg, nil
+}
+
+// parse parses message, returning the descriptor of the written type and the payload.
+func (d *Deserializer) parse(message []byte) (protoreflect.MessageDescriptor, []byte, error) {
+	id, data, err := schemaregistry.ParseWire(message)
+	if err != nil {
+		return nil, nil, err
+	}
+	indexes, payload, err := ParseMessageIndexes(data)
+	if err != nil {
+		return nil, nil, err
+	}
+	fd, err := d.file(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	md, err := messageByIndexes(fd, indexes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return md, payload, nil
+}
+
+// file returns the compiled writer schema of id, with the schemas it references.
+func (d *Deserializer) file(id int) (protoreflect.FileDescriptor, error) {
+	if fd, ok := d.files.Load(id); ok {
+		return fd.(protoreflect.FileDescriptor), nil
+	}
+	graph, err := schemaregistry.ResolveReferences(d.registry, id)
+	if err != nil {
+		return nil, err
+	}
+	if graph.Schema.SchemaType != schemaregistry.Protobuf {
+		return nil, errors.Errorf("schema %d is %s, not Protobuf", id, graph.Schema.SchemaType)
+	}
+
+	sources := map[string]string{}
+	for _, reference := range graph.References {
+		sources[reference.Name] = reference.Schema.Schema
+	}
+	// the root file is named after the id, unlike any import path
+	path := "schema-" + strconv.Itoa(id) + ".proto"
+	sources[path] = graph.Schema.Schema
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(sources),
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid Protobuf schema %d", id)
+	}
+	d.files.Store(id, files[0])
+	return files[0], nil
+}
+
+// printSchema returns the .proto source of fd.
+func printSchema(fd protoreflect.FileDescriptor) (string, error) {
+	wrapped, err := desc.WrapFile(fd)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid Protobuf file %s", fd.Path())
+	}
+	schema, err := (&protoprint.Printer{}).PrintProtoToString(wrapped)
+	if err != nil {
+		return "", errors.Wrapf(err, "error printing Protobuf file %s", fd.Path())
+	}
+	return schema, nil
+}
+
+// isWellKnown reports whether path is a file of the well-known types, known by the registry and the compiler.
+func isWellKnown(path string) bool {
+	return strings.HasPrefix(path, "google/protobuf/")
+}
@@ -0,0 +1,332 @@
+package protobuf_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/larixsource/go-schema-registry"
+	"github.com/larixsource/go-schema-registry/protobuf"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var testProtos = map[string]string{
+	"example/common/point.proto": `syntax = "proto3";
+package example.common;
+
+message Point {
+  int32 x = 1;
+  int32 y = 2;
+}
+`,
+	"example/frames.proto": `syntax = "proto3";
+package example;
+
+import "example/common/point.proto";
+import "google/protobuf/timestamp.proto";
+
+message Frame {
+  bytes data = 1;
+  example.common.Point origin = 2;
+  google.protobuf.Timestamp time = 3;
+  repeated Tag tags = 4;
+
+  message Tag {
+    string name = 1;
+  }
+}
+
+message Other {
+  string name = 1;
+}
+`,
+}
+
+// testMessages returns the message descriptors of the test protos, by full name.
+func testMessages(t *testing.T) map[string]protoreflect.MessageDescriptor {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(testProtos),
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), "example/frames.proto")
+	require.Nil(t, err)
+	messages := map[string]protoreflect.MessageDescriptor{}
+	var add func(mds protoreflect.MessageDescriptors)
+	add = func(mds protoreflect.MessageDescriptors) {
+		for i := 0; i < mds.Len(); i++ {
+			messages[string(mds.Get(i).FullName())] = mds.Get(i)
+			add(mds.Get(i).Messages())
+		}
+	}
+	add(files[0].Messages())
+	add(files[0].Imports().Get(0).FileDescriptor.Messages())
+	return messages
+}
+
+// registryServer is a minimal registry, keeping the registered schemas in memory.
+type registryServer struct {
+	mu       sync.Mutex
+	schemas  []map[string]interface{} // by id-1: schema, schemaType, references
+	subjects map[string][]int         // ids by version-1
+}
+
+func newRegistryServer() *httptest.Server {
+	s := &registryServer{subjects: map[string][]int{}}
+	return httptest.NewServer(s)
+}
+
+func (s *registryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var segments []string
+	for _, segment := range strings.Split(strings.Trim(r.URL.EscapedPath(), "/"), "/") {
+		unescaped, _ := url.PathUnescape(segment)
+		segments = append(segments, unescaped)
+	}
+	notFound := func(code schemaregistry.ErrorCode) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{Code: code, Message: "not found"})
+	}
+	lookup := func(subject string, id int) map[string]interface{} {
+		resp := map[string]interface{}{"id": id}
+		for k, v := range s.schemas[id-1] {
+			resp[k] = v
+		}
+		if subject != "" {
+			resp["subject"] = subject
+			for i, versionID := range s.subjects[subject] {
+				if versionID == id {
+					resp["version"] = i + 1
+				}
+			}
+		}
+		return resp
+	}
+
+	switch {
+	case r.Method == "GET" && len(segments) == 3 && segments[0] == "schemas":
+		id, _ := strconv.Atoi(segments[2])
+		if id < 1 || id > len(s.schemas) {
+			notFound(schemaregistry.SchemaNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(lookup("", id))
+	case r.Method == "GET" && len(segments) == 4:
+		versions := s.subjects[segments[1]]
+		version, _ := strconv.Atoi(segments[3])
+		if version < 1 || version > len(versions) {
+			notFound(schemaregistry.VersionNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(lookup(segments[1], versions[version-1]))
+	case r.Method == "POST":
+		var msg map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&msg)
+		key, _ := json.Marshal(msg)
+		id := 0
+		for i, schema := range s.schemas {
+			if existing, _ := json.Marshal(schema); string(existing) == string(key) {
+				id = i + 1
+			}
+		}
+		subject := segments[1]
+		registered := false
+		for _, versionID := range s.subjects[subject] {
+			registered = registered || (id != 0 && versionID == id)
+		}
+		if len(segments) == 2 {
+			// lookup
+			if !registered {
+				notFound(schemaregistry.SchemaNotFound)
+				return
+			}
+			json.NewEncoder(w).Encode(lookup(subject, id))
+			return
+		}
+		if id == 0 {
+			s.schemas = append(s.schemas, msg)
+			id = len(s.schemas)
+		}
+		if !registered {
+			s.subjects[subject] = append(s.subjects[subject], id)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": id})
+	default:
+		notFound(schemaregistry.SubjectNotFound)
+	}
+}
+
+func newFrame(t *testing.T, messages map[string]protoreflect.MessageDescriptor) *dynamicpb.Message {
+	point := dynamicpb.NewMessage(messages["example.common.Point"])
+	point.Set(point.Descriptor().Fields().ByName("x"), protoreflect.ValueOfInt32(3))
+	point.Set(point.Descriptor().Fields().ByName("y"), protoreflect.ValueOfInt32(-4))
+
+	frame := dynamicpb.NewMessage(messages["example.Frame"])
+	fields := frame.Descriptor().Fields()
+	frame.Set(fields.ByName("data"), protoreflect.ValueOfBytes([]byte("abc")))
+	frame.Set(fields.ByName("origin"), protoreflect.ValueOfMessage(point))
+	tag := dynamicpb.NewMessage(messages["example.Frame.Tag"])
+	tag.Set(tag.Descriptor().Fields().ByName("name"), protoreflect.ValueOfString("hd"))
+	frame.Mutable(fields.ByName("tags")).List().Append(protoreflect.ValueOfMessage(tag))
+	return frame
+}
+
+func TestSerializer_RoundTrip(t *testing.T) {
+	ts := newRegistryServer()
+	defer ts.Close()
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+	messages := testMessages(t)
+
+	serializer := protobuf.NewSerializer(registry)
+	frame := newFrame(t, messages)
+	message, err := serializer.SerializeValue("frames", frame)
+	require.Nil(t, err)
+	// the imported file is registered first, and referenced by the schema of the frames
+	assert.Equal(t, []byte{0, 0, 0, 0, 2, 0}, message[:6])
+
+	point, err := registry.SubjectVersion("example/common/point.proto", 1)
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Protobuf, point.SchemaType)
+	frames, err := registry.SubjectVersion("frames-value", 1)
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Protobuf, frames.SchemaType)
+	assert.Equal(t, []schemaregistry.SchemaReference{{
+		Name:    "example/common/point.proto",
+		Subject: "example/common/point.proto",
+		Version: 1,
+	}}, frames.References)
+
+	deserializer := protobuf.NewDeserializer(registry)
+	decoded, err := deserializer.DeserializeDynamic(message)
+	require.Nil(t, err)
+	assert.Equal(t, protoreflect.FullName("example.Frame"), decoded.Descriptor().FullName())
+	assertSameMessage(t, frame, decoded)
+
+	typed := dynamicpb.NewMessage(messages["example.Frame"])
+	require.Nil(t, deserializer.Deserialize(message, typed))
+	assert.True(t, proto.Equal(frame, typed))
+}
+
+func TestSerializer_NestedMessage(t *testing.T) {
+	ts := newRegistryServer()
+	defer ts.Close()
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+	messages := testMessages(t)
+
+	serializer := protobuf.NewSerializer(registry)
+	tag := dynamicpb.NewMessage(messages["example.Frame.Tag"])
+	tag.Set(tag.Descriptor().Fields().ByName("name"), protoreflect.ValueOfString("hd"))
+	message, err := serializer.Serialize("tags-value", tag)
+	require.Nil(t, err)
+	// message indexes [0, 0]: Tag is the first message of Frame, the first message of the file
+	assert.Equal(t, []byte{4, 0, 0}, message[5:8])
+
+	other := dynamicpb.NewMessage(messages["example.Other"])
+	message, err = serializer.Serialize("tags-value", other)
+	require.Nil(t, err)
+	assert.Equal(t, []byte{2, 2}, message[5:7])
+
+	decoded, err := protobuf.NewDeserializer(registry).DeserializeDynamic(message)
+	require.Nil(t, err)
+	assert.Equal(t, protoreflect.FullName("example.Other"), decoded.Descriptor().FullName())
+}
+
+func TestSerializer_GeneratedMessage(t *testing.T) {
+	ts := newRegistryServer()
+	defer ts.Close()
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	serializer := protobuf.NewSerializer(registry,
+		protobuf.WithSubjectNameStrategy(schemaregistry.RecordNameStrategy))
+	timestamp := &timestamppb.Timestamp{Seconds: 1700000000, Nanos: 42}
+	message, err := serializer.SerializeValue("events", timestamp)
+	require.Nil(t, err)
+
+	ss, err := registry.SubjectVersion("google.protobuf.Timestamp", 1)
+	require.Nil(t, err)
+	assert.Equal(t, schemaregistry.Protobuf, ss.SchemaType)
+
+	deserializer := protobuf.NewDeserializer(registry)
+	var decoded timestamppb.Timestamp
+	require.Nil(t, deserializer.Deserialize(message, &decoded))
+	assert.True(t, proto.Equal(timestamp, &decoded))
+
+	// decoding into another type fails
+	other := dynamicpb.NewMessage(testMessages(t)["example.Other"])
+	assert.Error(t, deserializer.Deserialize(message, other))
+}
+
+func TestSerializer_NoAutoRegister(t *testing.T) {
+	ts := newRegistryServer()
+	defer ts.Close()
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	serializer := protobuf.NewSerializer(registry, protobuf.AutoRegisterSchemas(false))
+	_, err = serializer.Serialize("events-value", &timestamppb.Timestamp{})
+	var apiErr *schemaregistry.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, schemaregistry.SchemaNotFound, apiErr.Code)
+
+	// registered by another serializer
+	_, err = protobuf.NewSerializer(registry).Serialize("events-value", &timestamppb.Timestamp{})
+	require.Nil(t, err)
+	_, err = serializer.Serialize("events-value", &timestamppb.Timestamp{})
+	assert.Nil(t, err)
+}
+
+func TestMessageIndexes(t *testing.T) {
+	tests := []struct {
+		indexes []int
+		encoded []byte
+	}{
+		{[]int{0}, []byte{0}},
+		{[]int{1}, []byte{2, 2}},
+		{[]int{0, 0}, []byte{4, 0, 0}},
+		{[]int{2, 1}, []byte{4, 4, 2}},
+		{[]int{70}, []byte{2, 140, 1}},
+	}
+	for _, test := range tests {
+		encoded := protobuf.AppendMessageIndexes(nil, test.indexes)
+		assert.Equal(t, test.encoded, encoded)
+
+		indexes, payload, err := protobuf.ParseMessageIndexes(append(encoded, 0xff))
+		require.Nil(t, err)
+		assert.Equal(t, test.indexes, indexes)
+		assert.Equal(t, []byte{0xff}, payload)
+	}
+
+	for _, invalid := range [][]byte{nil, {4, 2}, {1}, {0x80}} {
+		_, _, err := protobuf.ParseMessageIndexes(invalid)
+		assert.Equal(t, protobuf.ErrInvalidMessageIndexes, err)
+	}
+}
+
+// assertSameMessage asserts that expected and actual have the same type name and encoding (they may have different
+// descriptors, compiled from different sources).
+func assertSameMessage(t *testing.T, expected proto.Message, actual proto.Message) {
+	assert.Equal(t, expected.ProtoReflect().Descriptor().FullName(), actual.ProtoReflect().Descriptor().FullName())
+	deterministic := proto.MarshalOptions{Deterministic: true}
+	expectedData, err := deterministic.Marshal(expected)
+	require.Nil(t, err)
+	actualData, err := deterministic.Marshal(actual)
+	require.Nil(t, err)
+	assert.Equal(t, expectedData, actualData)
+}
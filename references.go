@@ -0,0 +1,107 @@
+package schemaregistry
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// ReferenceGraph is a schema with all the schemas it references, directly or transitively.
+type ReferenceGraph struct {
+	// Schema is the root schema.
+	Schema *SubjectSchema
+
+	// References are the referenced schemas, each one after the schemas it references, so they can be parsed in
+	// order.
+	References []*ResolvedReference
+}
+
+// ResolvedReference is a referenced schema, with the name it's referenced by.
+type ResolvedReference struct {
+	// Name is the name of the reference.
+	Name string
+
+	// Schema is the referenced schema.
+	Schema *SubjectSchema
+}
+
+// ResolveReferences gets the schema identified by id, with all the schemas it references, directly or transitively.
+// A schema referenced several times by the same name is only got once. Reference cycles, and names referencing
+// different schemas, are errors.
+func ResolveReferences(registry Registry, id int) (*ReferenceGraph, error) {
+	root, err := registry.SchemaByID(id)
+	if err != nil {
+		return nil, err
+	}
+	return resolveReferences(root, registry.SubjectVersion)
+}
+
+// ResolveReferencesContext is like ResolveReferences, with a context-aware registry.
+func ResolveReferencesContext(ctx context.Context, registry ContextRegistry, id int) (*ReferenceGraph, error) {
+	root, err := registry.SchemaByIDContext(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return resolveReferences(root, func(subject string, version int) (*SubjectSchema, error) {
+		return registry.SubjectVersionContext(ctx, subject, version)
+	})
+}
+
+func resolveReferences(root *SubjectSchema, subjectVersion func(subject string,
+	version int) (*SubjectSchema, error)) (*ReferenceGraph, error) {
+	r := &referenceResolver{
+		subjectVersion: subjectVersion,
+		graph:          &ReferenceGraph{Schema: root},
+		names:          map[string]SchemaReference{},
+		resolving:      map[string]bool{},
+	}
+	for _, ref := range root.References {
+		err := r.resolve(ref)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return r.graph, nil
+}
+
+type referenceResolver struct {
+	subjectVersion func(subject string, version int) (*SubjectSchema, error)
+	graph          *ReferenceGraph
+
+	// names are the references seen, by name
+	names map[string]SchemaReference
+
+	// resolving are the names of the references being resolved (the path from the root)
+	resolving map[string]bool
+}
+
+// resolve adds the schema of ref to the graph, after the schemas it references.
+func (r *referenceResolver) resolve(ref SchemaReference) error {
+	if seen, ok := r.names[ref.Name]; ok {
+		if seen != ref {
+			return errors.Errorf("reference %s is both %s version %d and %s version %d", ref.Name, seen.Subject,
+				seen.Version, ref.Subject, ref.Version)
+		}
+		if r.resolving[ref.Name] {
+			return errors.Errorf("reference cycle at %s", ref.Name)
+		}
+		return nil
+	}
+	r.names[ref.Name] = ref
+	r.resolving[ref.Name] = true
+
+	ss, err := r.subjectVersion(ref.Subject, ref.Version)
+	if err != nil {
+		return errors.Wrapf(err, "error resolving reference %s", ref.Name)
+	}
+	for _, child := range ss.References {
+		err = r.resolve(child)
+		if err != nil {
+			return err
+		}
+	}
+
+	r.resolving[ref.Name] = false
+	r.graph.References = append(r.graph.References, &ResolvedReference{Name: ref.Name, Schema: ss})
+	return nil
+}
@@ -0,0 +1,143 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_RegisterSubjectSchemaReferences(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]interface{}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&msg))
+		assert.Equal(t, []interface{}{map[string]interface{}{
+			"name":    "com.example.Point",
+			"subject": "point",
+			"version": float64(2),
+		}}, msg["references"])
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 5})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	id, err := registry.RegisterSubjectSchema("frames-value", testSchema, schemaregistry.WithReferences(
+		schemaregistry.SchemaReference{Name: "com.example.Point", Subject: "point", Version: 2}))
+	require.Nil(t, err)
+	assert.Equal(t, 5, id)
+}
+
+func TestRegistry_SubjectVersionReferences(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"subject": "frames-value", "id": 5, "version": 1, "schema": "{}",
+			"references": [{"name": "com.example.Point", "subject": "point", "version": 2}]}`))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	ss, err := registry.SubjectVersion("frames-value", 1)
+	require.Nil(t, err)
+	assert.Equal(t, []schemaregistry.SchemaReference{{Name: "com.example.Point", Subject: "point", Version: 2}},
+		ss.References)
+}
+
+func TestRegistry_ReferencedBy(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/subjects/point/versions/latest/referencedby", r.URL.String())
+		w.Write([]byte(`[5, 7]`))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	ids, err := registry.ReferencedBy("point", schemaregistry.Latest)
+	require.Nil(t, err)
+	assert.Equal(t, []int{5, 7}, ids)
+}
+
+func TestResolveReferences(t *testing.T) {
+	ref := func(name string, subject string, version int) schemaregistry.SchemaReference {
+		return schemaregistry.SchemaReference{Name: name, Subject: subject, Version: version}
+	}
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("SchemaByID", 10).Return(&schemaregistry.SubjectSchema{
+		ID:         10,
+		Schema:     "root",
+		References: []schemaregistry.SchemaReference{ref("a.proto", "a", 1), ref("b.proto", "b", 2)},
+	}, nil)
+	registry.On("SubjectVersion", "a", 1).Return(&schemaregistry.SubjectSchema{
+		Subject: "a", Version: 1, ID: 11, Schema: "a",
+	}, nil).Once()
+	registry.On("SubjectVersion", "b", 2).Return(&schemaregistry.SubjectSchema{
+		Subject: "b", Version: 2, ID: 12, Schema: "b",
+		References: []schemaregistry.SchemaReference{ref("a.proto", "a", 1), ref("c.proto", "c", 1)},
+	}, nil).Once()
+	registry.On("SubjectVersion", "c", 1).Return(&schemaregistry.SubjectSchema{
+		Subject: "c", Version: 1, ID: 13, Schema: "c",
+	}, nil).Once()
+
+	graph, err := schemaregistry.ResolveReferences(registry, 10)
+	require.Nil(t, err)
+	assert.Equal(t, "root", graph.Schema.Schema)
+	var names []string
+	for _, resolved := range graph.References {
+		names = append(names, resolved.Name)
+		assert.Equal(t, resolved.Schema.Subject, resolved.Schema.Schema)
+	}
+	// dependencies first, each schema once
+	assert.Equal(t, []string{"a.proto", "c.proto", "b.proto"}, names)
+	registry.AssertExpectations(t)
+}
+
+func TestResolveReferences_Errors(t *testing.T) {
+	ref := func(name string, subject string, version int) schemaregistry.SchemaReference {
+		return schemaregistry.SchemaReference{Name: name, Subject: subject, Version: version}
+	}
+	registry := &schemaregistry.MockRegistry{}
+	registry.On("SchemaByID", 1).Return(&schemaregistry.SubjectSchema{
+		ID:         1,
+		References: []schemaregistry.SchemaReference{ref("a.proto", "a", 1)},
+	}, nil)
+	registry.On("SubjectVersion", "a", 1).Return(&schemaregistry.SubjectSchema{
+		Subject: "a", Version: 1,
+		References: []schemaregistry.SchemaReference{ref("a.proto", "a", 1)},
+	}, nil)
+	registry.On("SchemaByID", 2).Return(&schemaregistry.SubjectSchema{
+		ID:         2,
+		References: []schemaregistry.SchemaReference{ref("b.proto", "b", 1), ref("b.proto", "b", 2)},
+	}, nil)
+	registry.On("SubjectVersion", "b", 1).Return(&schemaregistry.SubjectSchema{Subject: "b", Version: 1}, nil)
+	registry.On("SchemaByID", 3).Return(&schemaregistry.SubjectSchema{
+		ID:         3,
+		References: []schemaregistry.SchemaReference{ref("c.proto", "c", 1)},
+	}, nil)
+	registry.On("SubjectVersion", "c", 1).Return((*schemaregistry.SubjectSchema)(nil), &schemaregistry.APIError{
+		Code:    schemaregistry.VersionNotFound,
+		Message: "Version not found",
+	})
+
+	_, err := schemaregistry.ResolveReferences(registry, 1)
+	assert.EqualError(t, err, "reference cycle at a.proto")
+
+	_, err = schemaregistry.ResolveReferences(registry, 2)
+	assert.EqualError(t, err, "reference b.proto is both b version 1 and b version 2")
+
+	_, err = schemaregistry.ResolveReferences(registry, 3)
+	var apiErr *schemaregistry.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, schemaregistry.VersionNotFound, apiErr.Code)
+}
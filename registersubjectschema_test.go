@@ -86,10 +86,11 @@ func TestRegistry_RegisterSubjectSchemaErrInvalidAvroSchema(t *testing.T) {
 	require.Nil(t, err)
 
 	_, err = registry.RegisterSubjectSchema("frames-value", testSchema)
-	apiErr, ok := err.(*schemaregistry.APIError)
+	schemaErr, ok := err.(*schemaregistry.InvalidSchemaError)
 	require.True(t, ok)
-	assert.Equal(t, schemaregistry.InvalidAvroSchema, apiErr.Code)
-	assert.Equal(t, "Invalid Avro schema", apiErr.Message)
+	assert.Equal(t, schemaregistry.Avro, schemaErr.SchemaType)
+	assert.Equal(t, schemaregistry.InvalidSchema, schemaErr.Code)
+	assert.Equal(t, "Invalid Avro schema", schemaErr.Message)
 }
 
 func TestRegistry_RegisterSubjectSchemaErrBackendStore(t *testing.T) {
@@ -0,0 +1,97 @@
+package schemaregistry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetryPolicy configures how the failed requests are retried. Only retryable errors (see IsRetryable) of idempotent
+// requests are retried: all the lookups, the registration of schemas (registering an identical schema returns the
+// same id) and the config updates. Deletions are never retried.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts of a request, including the first one. Values <= 1 disable retries.
+	MaxAttempts int
+
+	// InitialBackoff is the wait before the first retry. It's doubled for each subsequent retry. Zero means the
+	// InitialBackoff of DefaultRetryPolicy.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the wait between retries. Zero means no cap.
+	MaxBackoff time.Duration
+
+	// Jitter randomizes each wait by up to this fraction of it (e.g. 0.2 means ±20%), to spread the retries of
+	// concurrent clients. It must be in [0, 1].
+	Jitter float64
+
+	// PerAttemptTimeout bounds each attempt. Zero means no timeout (other than the one of the operation).
+	PerAttemptTimeout time.Duration
+}
+
+// DefaultRetryPolicy is a reasonable RetryPolicy: 3 attempts, with exponential backoff starting at 100ms, capped at
+// 2s, and 20% jitter.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+	Jitter:         0.2,
+}
+
+// WithRetry sets the retry policy of the Registry. By default, failed requests are not retried.
+func WithRetry(policy RetryPolicy) Option {
+	return func(r *registry) error {
+		if policy.InitialBackoff < 0 || policy.MaxBackoff < 0 || policy.PerAttemptTimeout < 0 {
+			return errors.New("invalid retry policy: negative durations")
+		}
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return errors.Errorf("invalid retry policy: jitter %f not in [0, 1]", policy.Jitter)
+		}
+		if policy.InitialBackoff == 0 {
+			policy.InitialBackoff = DefaultRetryPolicy.InitialBackoff
+		}
+		r.retry = policy
+		return nil
+	}
+}
+
+// backoff returns the wait after the failed attempt number attempt (starting at 1).
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	// without cap, the doubling stops before overflowing (even with the jitter)
+	for i := 1; i < attempt && (p.MaxBackoff == 0 || d < p.MaxBackoff) && d < math.MaxInt64/4; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if p.Jitter > 0 {
+		delta := p.Jitter * float64(d)
+		d = time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+	}
+	return d
+}
+
+// idempotent reports whether a request with method can be safely retried. The POSTs of the API are either lookups
+// or registrations of schemas, which are idempotent.
+func idempotent(method string) bool {
+	return method != http.MethodDelete
+}
+
+// sleep waits for d, returning false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
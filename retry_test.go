@@ -0,0 +1,234 @@
+package schemaregistry_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testRetryPolicy = schemaregistry.RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     10 * time.Millisecond,
+	Jitter:         0.5,
+}
+
+func TestIsRetryable(t *testing.T) {
+	t.Parallel()
+	retryable := []error{
+		&schemaregistry.APIError{Code: schemaregistry.OperationTimedOut},
+		&schemaregistry.APIError{Code: schemaregistry.FwdRequestToMasterErr},
+		&schemaregistry.APIError{Code: schemaregistry.BackendStoreErr, StatusCode: http.StatusInternalServerError},
+		&schemaregistry.APIError{Code: http.StatusBadGateway, StatusCode: http.StatusBadGateway},
+		&schemaregistry.APIError{Code: http.StatusTooManyRequests, StatusCode: http.StatusTooManyRequests},
+		&schemaregistry.APIError{Code: 500, StatusCode: http.StatusInternalServerError},
+		errors.Wrap(&schemaregistry.APIError{Code: schemaregistry.OperationTimedOut}, "wrapped"),
+		errors.Wrap(syscall.ECONNREFUSED, "error in GET"),
+		errors.Wrap(syscall.ECONNRESET, "error in GET"),
+		errors.Wrap(io.ErrUnexpectedEOF, "error in GET"),
+	}
+	for _, err := range retryable {
+		assert.True(t, schemaregistry.IsRetryable(err), "%v", err)
+	}
+
+	notRetryable := []error{
+		nil,
+		&schemaregistry.APIError{Code: schemaregistry.SubjectNotFound, StatusCode: http.StatusNotFound},
+		&schemaregistry.APIError{Code: schemaregistry.InvalidAvroSchema, StatusCode: http.StatusUnprocessableEntity},
+		&schemaregistry.APIError{Code: http.StatusConflict, StatusCode: http.StatusConflict},
+		errors.Wrap(context.Canceled, "error in GET"),
+		errors.Wrap(context.DeadlineExceeded, "error in GET"),
+		errors.New("error decoding response"),
+		&schemaregistry.APIError{Code: 500},
+	}
+	for _, err := range notRetryable {
+		assert.False(t, schemaregistry.IsRetryable(err), "%v", err)
+	}
+}
+
+func TestRegistry_RetryRegisterSubjectSchema(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]string
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		require.Nil(t, err)
+		assert.Equal(t, testSchema, msg["schema"])
+
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(&schemaregistry.APIError{
+				Code:    schemaregistry.FwdRequestToMasterErr,
+				Message: "Error while forwarding the request to the master",
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 1})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithRetry(testRetryPolicy))
+	require.Nil(t, err)
+
+	id, err := registry.RegisterSubjectSchema("frames-value", testSchema)
+	require.Nil(t, err)
+	assert.Equal(t, 1, id)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_RetryExhausted(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.OperationTimedOut,
+			Message: "Operation timed out",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithRetry(testRetryPolicy))
+	require.Nil(t, err)
+
+	_, err = registry.Schema(1)
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.Equal(t, schemaregistry.OperationTimedOut, apiErr.Code)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_RetryNotRetryable(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.SubjectNotFound,
+			Message: "Subject not found",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithRetry(testRetryPolicy))
+	require.Nil(t, err)
+
+	_, err = registry.CheckSubjectSchema("frames-value", testSchema)
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_RetryEmptyBody(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithRetry(testRetryPolicy))
+	require.Nil(t, err)
+
+	// a 200 without body is a broken response, not a connection closed by the server
+	_, err = registry.Subjects()
+	assert.Error(t, err)
+	assert.False(t, schemaregistry.IsRetryable(err))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_RetryNotIdempotent(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithRetry(testRetryPolicy))
+	require.Nil(t, err)
+
+	_, err = registry.DeleteSubjectConfig("frames-value")
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_RetryPerAttemptTimeout(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+			return
+		}
+		json.NewEncoder(w).Encode([]string{"frames-value"})
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	policy := testRetryPolicy
+	policy.PerAttemptTimeout = 50 * time.Millisecond
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithRetry(policy))
+	require.Nil(t, err)
+
+	subjects, err := registry.Subjects()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"frames-value"}, subjects)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
+
+func TestWithRetryInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := schemaregistry.New("http://localhost:8081", schemaregistry.WithRetry(schemaregistry.RetryPolicy{Jitter: 2}))
+	assert.Error(t, err)
+}
+
+func TestRetryPolicy_BackoffUncapped(t *testing.T) {
+	t.Parallel()
+	policy := schemaregistry.RetryPolicy{MaxAttempts: 100, InitialBackoff: 100 * time.Millisecond, Jitter: 1}
+	for attempt := 1; attempt <= 100; attempt++ {
+		assert.True(t, policy.Backoff(attempt) >= 0, "attempt %d", attempt)
+	}
+	policy.Jitter = 0
+	assert.True(t, policy.Backoff(100) >= policy.Backoff(30))
+}
+
+func TestRegistry_RetryDefaultBackoff(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode([]string{"frames-value"})
+	}))
+	defer ts.Close()
+
+	// without InitialBackoff, the one of DefaultRetryPolicy is used
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithRetry(schemaregistry.RetryPolicy{MaxAttempts: 2}))
+	require.Nil(t, err)
+
+	start := time.Now()
+	_, err = registry.Subjects()
+	require.Nil(t, err)
+	assert.True(t, time.Since(start) >= schemaregistry.DefaultRetryPolicy.InitialBackoff)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
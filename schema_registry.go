@@ -4,20 +4,29 @@ package schemaregistry
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/pkg/errors"
 )
 
 // Latest represents the version "latest" in the operations SubjectVersion(subject, version) and
-// TestCompatibility(subject, version, schema) of Registry
+// TestCompatibility(subject, version, schema) of Registry. It's sent to the registry as the "latest" path segment.
 const Latest = 0
 
 // Compatibility is the type of compatibility supported by the registry. The schema registry server can enforce certain
 // compatibility rules when new schemas are registered in a subject.
+//
 //go:generate stringer -type=Compatibility
 type Compatibility int
 
@@ -36,8 +45,96 @@ const (
 	// Backward means backward compatibility (default): A new schema is backwards compatible if it can be used to
 	// read the data written in the latest registered schema.
 	Backward
+
+	// BackwardTransitive means transitive backward compatibility: A new schema is backwards compatible if it can be
+	// used to read the data written in all the previously registered schemas.
+	BackwardTransitive
+
+	// ForwardTransitive means transitive forward compatibility: A new schema is forward compatible if all the
+	// previously registered schemas can read data written in this schema.
+	ForwardTransitive
+
+	// FullTransitive means transitive full compatibility: A new schema is fully compatible if it’s both transitive
+	// backward and transitive forward compatible with all the previously registered schemas.
+	FullTransitive
 )
 
+// compatibilityLevels are the names used by the registry for each Compatibility.
+var compatibilityLevels = [...]string{
+	None:               "NONE",
+	Full:               "FULL",
+	Forward:            "FORWARD",
+	Backward:           "BACKWARD",
+	BackwardTransitive: "BACKWARD_TRANSITIVE",
+	ForwardTransitive:  "FORWARD_TRANSITIVE",
+	FullTransitive:     "FULL_TRANSITIVE",
+}
+
+// MarshalText encodes the compatibility as the level name used by the registry (NONE, FULL, FORWARD_TRANSITIVE,
+// etc.).
+func (c Compatibility) MarshalText() ([]byte, error) {
+	if c < 0 || int(c) >= len(compatibilityLevels) {
+		return nil, errors.Errorf("invalid compatibility: %d", c)
+	}
+	return []byte(compatibilityLevels[c]), nil
+}
+
+// UnmarshalText decodes a level name used by the registry (NONE, FULL, FORWARD_TRANSITIVE, etc.).
+func (c *Compatibility) UnmarshalText(text []byte) error {
+	level := strings.ToUpper(string(text))
+	for i, name := range compatibilityLevels {
+		if name == level {
+			*c = Compatibility(i)
+			return nil
+		}
+	}
+	return errors.Errorf("invalid compatibility level: %s", text)
+}
+
+// SchemaType is the type of a schema. The registry assumes Avro for the schemas without type, so Avro is the zero
+// value.
+//
+//go:generate stringer -type=SchemaType
+type SchemaType int
+
+const (
+	// Avro is the type of Avro schemas (the default).
+	Avro SchemaType = iota
+
+	// Protobuf is the type of Protocol Buffers schemas (.proto files).
+	Protobuf
+
+	// JSON is the type of JSON Schema schemas.
+	JSON
+)
+
+// schemaTypes are the names used by the registry for each SchemaType.
+var schemaTypes = [...]string{
+	Avro:     "AVRO",
+	Protobuf: "PROTOBUF",
+	JSON:     "JSON",
+}
+
+// MarshalText encodes the schema type as the name used by the registry (AVRO, PROTOBUF or JSON).
+func (t SchemaType) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(schemaTypes) {
+		return nil, errors.Errorf("invalid schema type: %d", t)
+	}
+	return []byte(schemaTypes[t]), nil
+}
+
+// UnmarshalText decodes a schema type name used by the registry (AVRO, PROTOBUF or JSON).
+func (t *SchemaType) UnmarshalText(text []byte) error {
+	name := strings.ToUpper(string(text))
+	for i, typeName := range schemaTypes {
+		if typeName == name {
+			*t = SchemaType(i)
+			return nil
+		}
+	}
+	return errors.Errorf("invalid schema type: %s", text)
+}
+
 //go:generate stringer -type=ErrorCode
 type ErrorCode int
 
@@ -51,8 +148,32 @@ const (
 	// SchemaNotFound status code (Schema not found)
 	SchemaNotFound ErrorCode = 40403
 
-	// InvalidAvroSchema status code (Invalid Avro schema)
-	InvalidAvroSchema ErrorCode = 42201
+	// SubjectSoftDeleted status code (Subject was soft deleted, set permanent=true to delete permanently)
+	SubjectSoftDeleted ErrorCode = 40404
+
+	// SubjectNotSoftDeleted status code (Subject was not soft deleted before being permanently deleted)
+	SubjectNotSoftDeleted ErrorCode = 40405
+
+	// VersionSoftDeleted status code (Version was soft deleted, set permanent=true to delete permanently)
+	VersionSoftDeleted ErrorCode = 40406
+
+	// VersionNotSoftDeleted status code (Version was not soft deleted before being permanently deleted)
+	VersionNotSoftDeleted ErrorCode = 40407
+
+	// SubjectLevelCompatibilityNotConfigured status code (Subject level compatibility not configured)
+	SubjectLevelCompatibilityNotConfigured ErrorCode = 40408
+
+	// SubjectLevelModeNotConfigured status code (Subject level mode not configured)
+	SubjectLevelModeNotConfigured ErrorCode = 40409
+
+	// InvalidSchema status code (Invalid schema, of any type). Operations taking a schema return it as an
+	// *InvalidSchemaError.
+	InvalidSchema ErrorCode = 42201
+
+	// InvalidAvroSchema status code (Invalid Avro schema).
+	//
+	// Deprecated: the registry uses the code for all the schema types, use InvalidSchema.
+	InvalidAvroSchema = InvalidSchema
 
 	// InvalidVersion status code (Invalid version)
 	InvalidVersion ErrorCode = 42202
@@ -60,6 +181,16 @@ const (
 	// InvalidCompatibilityLevel status code (Invalid compatibility level)
 	InvalidCompatibilityLevel ErrorCode = 42203
 
+	// InvalidMode status code (Invalid mode)
+	InvalidMode ErrorCode = 42204
+
+	// OperationNotPermitted status code (Operation not permitted by the mode of the registry or subject). It's
+	// returned as a *ModeError.
+	OperationNotPermitted ErrorCode = 42205
+
+	// ReferenceExists status code (One or more schemas reference the schema being deleted)
+	ReferenceExists ErrorCode = 42206
+
 	// BackendStoreErr status code (Error in the backend data store)
 	BackendStoreErr ErrorCode = 50001
 
@@ -77,13 +208,86 @@ type APIError struct {
 
 	// Message is the error message
 	Message string `json:"message"`
+
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int `json:"-"`
+
+	// Body is a snippet of the raw response body. It's only set when the response isn't a JSON error message (like
+	// the HTML error page of a proxy), in which case Code is the HTTP status code.
+	Body string `json:"-"`
+}
+
+// IsRetryable reports whether err is a transient error, so the failed operation may succeed if retried. These are
+// the retryable errors:
+//
+//   - an *APIError with code OperationTimedOut or FwdRequestToMasterErr, or of a 5xx or 429 response.
+//   - a network timeout, a connection refused or reset, or a connection closed by the server mid-response. An empty
+//     response body isn't retryable.
+//
+// Context cancellations and deadlines are not retryable. err may be wrapped with github.com/pkg/errors.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Code == OperationTimedOut, apiErr.Code == FwdRequestToMasterErr:
+			return true
+		case apiErr.StatusCode >= 500, apiErr.StatusCode == http.StatusTooManyRequests:
+			return true
+		}
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
 }
 
 func (e *APIError) Error() string {
+	if e.Body != "" {
+		return fmt.Sprintf("Schema Registry API error, code: %d message: %s body: %q", e.Code, e.Message, e.Body)
+	}
 	return fmt.Sprintf("Schema Registry API error, code: %d message: %s", e.Code, e.Message)
 }
 
-// SubjectSchema holds an Avro schema string along with its globally unique identifier and its version under a specific
+// InvalidSchemaError is the error of the operations taking a schema (RegisterSubjectSchema, CheckSubjectSchema,
+// etc.) when the registry rejects the schema as invalid. It wraps the *APIError (with code InvalidSchema) and reports
+// the type of the schema.
+type InvalidSchemaError struct {
+	*APIError
+
+	// SchemaType is the type of the invalid schema.
+	SchemaType SchemaType
+}
+
+func (e *InvalidSchemaError) Error() string {
+	return fmt.Sprintf("invalid %s schema: %s", e.SchemaType, e.APIError.Error())
+}
+
+// Unwrap returns the *APIError.
+func (e *InvalidSchemaError) Unwrap() error {
+	return e.APIError
+}
+
+// schemaError returns err as an *InvalidSchemaError if it's an *APIError with code InvalidSchema, of a schema of
+// type schemaType.
+func schemaError(err error, schemaType SchemaType) error {
+	if apiErr, ok := err.(*APIError); ok && apiErr.Code == InvalidSchema {
+		return &InvalidSchemaError{APIError: apiErr, SchemaType: schemaType}
+	}
+	return err
+}
+
+// SubjectSchema holds a schema string along with its globally unique identifier and its version under a specific
 // subject.
 type SubjectSchema struct {
 	// Subject is the subject name. A subject refers to the name under which the schema is registered. If you are
@@ -97,31 +301,184 @@ type SubjectSchema struct {
 	// Version is the version of the schema in the subject.
 	Version int `json:"version"`
 
-	// Schema is the Avro schema string
+	// Schema is the schema string
 	Schema string `json:"schema"`
+
+	// SchemaType is the type of the schema.
+	SchemaType SchemaType `json:"schemaType,omitempty"`
+
+	// References are the schemas referenced by the schema.
+	References []SchemaReference `json:"references,omitempty"`
+}
+
+// SchemaReference is a reference of a schema to another schema, registered as a version of a subject.
+type SchemaReference struct {
+	// Name is the name the schema uses to reference the other schema: the full name of an Avro named type, the
+	// import path of a Protobuf file, or the $ref URL of a JSON Schema.
+	Name string `json:"name"`
+
+	// Subject is the subject of the referenced schema.
+	Subject string `json:"subject"`
+
+	// Version is the version of the referenced schema in the subject.
+	Version int `json:"version"`
+}
+
+// CompatibilityResult is the outcome of a compatibility test.
+type CompatibilityResult struct {
+	// IsCompatible is true if the schema is compatible.
+	IsCompatible bool `json:"is_compatible"`
+
+	// Messages are the reasons of the incompatibility, only returned by verbose checks.
+	Messages []string `json:"messages,omitempty"`
 }
 
 // Config holds the configuration (global or of a subject)
 type Config struct {
-	// Compatibility is the compatibility level in use.
-	Compatibility Compatibility
+	// Compatibility is the compatibility level in use. Nil means not set, so updates without it keep the current
+	// level (see CompatibilityLevel).
+	Compatibility *Compatibility `json:"compatibility,omitempty"`
+
+	// Normalize makes the registry normalize schemas when registering or looking them up. Nil means not set.
+	Normalize *bool `json:"normalize,omitempty"`
+
+	// Alias is the subject this subject is an alias of (subject configs only).
+	Alias string `json:"alias,omitempty"`
+
+	// CompatibilityGroup is the name of the metadata property used to group schemas for compatibility checks. Only
+	// the schemas of the same group are checked against each other.
+	CompatibilityGroup string `json:"compatibilityGroup,omitempty"`
+
+	// DefaultMetadata is the metadata used for new schemas that don't specify it.
+	DefaultMetadata *Metadata `json:"defaultMetadata,omitempty"`
+
+	// OverrideRuleSet is the rule set applied to new schemas, overriding the rules they specify.
+	OverrideRuleSet *RuleSet `json:"overrideRuleSet,omitempty"`
+}
+
+// UnmarshalJSON decodes a Config, accepting the level both as "compatibility" (as returned by updates) and as
+// "compatibilityLevel" (as returned by lookups).
+func (c *Config) UnmarshalJSON(data []byte) error {
+	type config Config
+	var msg struct {
+		config
+		CompatibilityLevel *Compatibility `json:"compatibilityLevel"`
+	}
+	err := json.Unmarshal(data, &msg)
+	if err != nil {
+		return err
+	}
+	*c = Config(msg.config)
+	if msg.CompatibilityLevel != nil {
+		c.Compatibility = msg.CompatibilityLevel
+	}
+	return nil
+}
+
+// CompatibilityLevel returns a pointer to level, to set the Compatibility of a Config:
+//
+//	config := &Config{Compatibility: CompatibilityLevel(Full)}
+func CompatibilityLevel(level Compatibility) *Compatibility {
+	return &level
+}
+
+// Metadata holds the metadata of a schema: tags by path, properties and sensitive properties.
+type Metadata struct {
+	// Tags are the tags of the schema, by path (field path, record name, etc.).
+	Tags map[string][]string `json:"tags,omitempty"`
+
+	// Properties are arbitrary key-value properties.
+	Properties map[string]string `json:"properties,omitempty"`
+
+	// Sensitive are the names of the properties that hold sensitive values.
+	Sensitive []string `json:"sensitive,omitempty"`
+}
+
+// RuleSet holds the data contract rules of a schema.
+type RuleSet struct {
+	// MigrationRules are the rules applied when migrating data between schema versions.
+	MigrationRules []Rule `json:"migrationRules,omitempty"`
+
+	// DomainRules are the rules applied to the data of the schema.
+	DomainRules []Rule `json:"domainRules,omitempty"`
+}
+
+// Rule is a data contract rule.
+type Rule struct {
+	// Name is the rule name.
+	Name string `json:"name"`
+
+	// Doc is an optional description.
+	Doc string `json:"doc,omitempty"`
+
+	// Kind is either TRANSFORM or CONDITION.
+	Kind string `json:"kind,omitempty"`
+
+	// Mode is when the rule applies: UPGRADE, DOWNGRADE, UPDOWN, WRITE, READ or WRITEREAD.
+	Mode string `json:"mode,omitempty"`
+
+	// Type is the rule executor type, like CEL or ENCRYPT.
+	Type string `json:"type,omitempty"`
+
+	// Tags are the tags the rule applies to.
+	Tags []string `json:"tags,omitempty"`
+
+	// Params are the executor parameters.
+	Params map[string]string `json:"params,omitempty"`
+
+	// Expr is the rule expression.
+	Expr string `json:"expr,omitempty"`
+
+	// OnSuccess is the action executed when the rule succeeds.
+	OnSuccess string `json:"onSuccess,omitempty"`
+
+	// OnFailure is the action executed when the rule fails.
+	OnFailure string `json:"onFailure,omitempty"`
+
+	// Disabled disables the rule.
+	Disabled bool `json:"disabled,omitempty"`
 }
 
 // Registry exposes the API operations of Schema Registry (https://github.com/confluentinc/schema-registry)
 //
 // Check http://docs.confluent.io/3.1.2/schema-registry/docs/api.html for details.
 type Registry interface {
-	// Schema gets a list of registered subjects.
+	// Schema gets the schema string identified by the input id. Schema ids are immutable, so implementations are
+	// free to cache the result.
 	Schema(id int) (string, error)
 
-	// Subjects gets a list of registered subjects.
-	Subjects() ([]string, error)
+	// SchemaByID gets the schema identified by the input id, with its type. The subject and version of the returned
+	// SubjectSchema aren't set. Like Schema, the result may be cached.
+	SchemaByID(id int) (*SubjectSchema, error)
+
+	// SchemaTypes gets the schema types supported by the registry.
+	SchemaTypes() ([]SchemaType, error)
+
+	// ReferencedBy gets the ids of the schemas that reference a specific version of the schema registered under this
+	// subject. Use Latest to get the references to the last registered version.
+	ReferencedBy(subject string, version int) ([]int, error)
+
+	// Subjects gets a list of registered subjects. Soft-deleted subjects are included with IncludeDeleted(), and
+	// the list can be filtered with SubjectPrefix(prefix).
+	Subjects(opts ...ListOption) ([]string, error)
+
+	// SubjectVersions gets a list of versions registered under the specified subject. Soft-deleted versions are
+	// included with IncludeDeleted().
+	SubjectVersions(subject string, opts ...ListOption) ([]int, error)
+
+	// SubjectVersion gets a specific version of the schema registered under this subject. Use Latest to get the
+	// last registered version.
+	SubjectVersion(subject string, version int) (*SubjectSchema, error)
 
-	// SubjectVersions gets a list of versions registered under the specified subject.
-	SubjectVersions(subject string) ([]int, error)
+	// DeleteSubject deletes the specified subject with all its versions, returning the deleted versions. The delete
+	// is soft (the versions are still listed with IncludeDeleted(), and the schemas are still got by id), unless
+	// Permanent() is given, for subjects already soft-deleted. Delete errors are returned as a *DeleteError.
+	DeleteSubject(subject string, opts ...DeleteOption) ([]int, error)
 
-	// SubjectVersion gets a specific version of the schema registered under this subject.
-	SubjectVersion(subject string, version int) (string, error)
+	// DeleteSubjectVersion deletes a specific version of the schema registered under this subject, returning the
+	// deleted version. Use Latest to delete the last registered version. Like DeleteSubject, the delete is soft unless
+	// Permanent() is given, and delete errors are returned as a *DeleteError.
+	DeleteSubjectVersion(subject string, version int, opts ...DeleteOption) (int, error)
 
 	// RegisterSubjectSchema registers a new schema under the specified subject. If successfully registered, this
 	// returns the unique identifier of this schema in the registry. The returned identifier should be used to
@@ -136,18 +493,26 @@ type Registry interface {
 	// When there are multiple instances of schema registry running in the same cluster, the schema registration
 	// request will be forwarded to one of the instances designated as the master. If the master is not available,
 	// the client will get an error code indicating that the forwarding has failed.
-	RegisterSubjectSchema(subject string, schema string) (int, error)
+	//
+	// The schema is Avro, unless another type is given with WithSchemaType. The schemas it references are given with
+	// WithReferences. If the registry rejects the schema as invalid, an *InvalidSchemaError is returned.
+	RegisterSubjectSchema(subject string, schema string, opts ...SchemaOption) (int, error)
 
 	// CheckSubjectSchema checks if a schema has already been registered under the specified subject. If so, this
 	// returns the schema string along with its globally unique identifier, its version under this subject and the
 	// subject name.
-	CheckSubjectSchema(subject string, schema string) (*SubjectSchema, error)
+	CheckSubjectSchema(subject string, schema string, opts ...SchemaOption) (*SubjectSchema, error)
 
 	// TestCompatibility tests an input schema against a particular version of a subject’s schema for compatibility.
 	// Note that the compatibility level applied for the check is the configured compatibility level for the subject
 	// (SubjectConfig(subject)). If this subject’s compatibility level was never changed, then the global
 	// compatibility level applies (Config()).
-	TestCompatibility(subject string, version int, schema string) (bool, error)
+	TestCompatibility(subject string, version int, schema string, opts ...SchemaOption) (bool, error)
+
+	// TestCompatibilityAll tests an input schema against all the versions of a subject’s schema, as required by the
+	// configured compatibility level (transitive or not). The check is verbose: when the schema is incompatible, the
+	// returned CompatibilityResult has the reasons reported by the registry.
+	TestCompatibilityAll(subject string, schema string, opts ...SchemaOption) (*CompatibilityResult, error)
 
 	// SetConfig updates the global compatibility level.
 	//
@@ -162,18 +527,267 @@ type Registry interface {
 	// SetSubjectConfig updates the compatibility level for the specified subject.
 	SetSubjectConfig(subject string, config *Config) (*Config, error)
 
-	// SubjectConfig gets the compatibility level for a subject.
+	// SubjectConfig gets the compatibility level for a subject. If the subject has no config of its own, an
+	// *APIError with code SubjectLevelCompatibilityNotConfigured is returned.
 	SubjectConfig(subject string) (*Config, error)
+
+	// DeleteSubjectConfig deletes the configuration of the specified subject, reverting it to the global one. The
+	// deleted configuration is returned.
+	DeleteSubjectConfig(subject string) (*Config, error)
+
+	// SetMode updates the global mode. The registry refuses some changes (like setting Import with schemas already
+	// registered), unless Force() is given.
+	SetMode(mode Mode, opts ...ModeOption) (Mode, error)
+
+	// Mode gets the global mode.
+	Mode() (Mode, error)
+
+	// SetSubjectMode updates the mode for the specified subject. Like SetMode, some changes require Force().
+	SetSubjectMode(subject string, mode Mode, opts ...ModeOption) (Mode, error)
+
+	// SubjectMode gets the mode for a subject. If the subject has no mode of its own, an *APIError with code
+	// SubjectLevelModeNotConfigured is returned.
+	SubjectMode(subject string) (Mode, error)
+
+	// DeleteSubjectMode deletes the mode of the specified subject, reverting it to the global one. The deleted mode
+	// is returned.
+	DeleteSubjectMode(subject string) (Mode, error)
+}
+
+// ContextRegistry is the context-aware variant of Registry. Each operation takes a context.Context that bounds the
+// requests to the registry (cancellation, deadline), and otherwise behaves like its Registry counterpart.
+type ContextRegistry interface {
+	Registry
+
+	// SchemaContext is like Registry.Schema, bound to ctx.
+	SchemaContext(ctx context.Context, id int) (string, error)
+
+	// SchemaByIDContext is like Registry.SchemaByID, bound to ctx.
+	SchemaByIDContext(ctx context.Context, id int) (*SubjectSchema, error)
+
+	// SchemaTypesContext is like Registry.SchemaTypes, bound to ctx.
+	SchemaTypesContext(ctx context.Context) ([]SchemaType, error)
+
+	// ReferencedByContext is like Registry.ReferencedBy, bound to ctx.
+	ReferencedByContext(ctx context.Context, subject string, version int) ([]int, error)
+
+	// SubjectsContext is like Registry.Subjects, bound to ctx.
+	SubjectsContext(ctx context.Context, opts ...ListOption) ([]string, error)
+
+	// SubjectVersionsContext is like Registry.SubjectVersions, bound to ctx.
+	SubjectVersionsContext(ctx context.Context, subject string, opts ...ListOption) ([]int, error)
+
+	// SubjectVersionContext is like Registry.SubjectVersion, bound to ctx.
+	SubjectVersionContext(ctx context.Context, subject string, version int) (*SubjectSchema, error)
+
+	// DeleteSubjectContext is like Registry.DeleteSubject, bound to ctx.
+	DeleteSubjectContext(ctx context.Context, subject string, opts ...DeleteOption) ([]int, error)
+
+	// DeleteSubjectVersionContext is like Registry.DeleteSubjectVersion, bound to ctx.
+	DeleteSubjectVersionContext(ctx context.Context, subject string, version int, opts ...DeleteOption) (int, error)
+
+	// RegisterSubjectSchemaContext is like Registry.RegisterSubjectSchema, bound to ctx.
+	RegisterSubjectSchemaContext(ctx context.Context, subject string, schema string, opts ...SchemaOption) (int,
+		error)
+
+	// CheckSubjectSchemaContext is like Registry.CheckSubjectSchema, bound to ctx.
+	CheckSubjectSchemaContext(ctx context.Context, subject string, schema string, opts ...SchemaOption) (*SubjectSchema,
+		error)
+
+	// TestCompatibilityContext is like Registry.TestCompatibility, bound to ctx.
+	TestCompatibilityContext(ctx context.Context, subject string, version int, schema string,
+		opts ...SchemaOption) (bool, error)
+
+	// TestCompatibilityAllContext is like Registry.TestCompatibilityAll, bound to ctx.
+	TestCompatibilityAllContext(ctx context.Context, subject string, schema string,
+		opts ...SchemaOption) (*CompatibilityResult, error)
+
+	// SetConfigContext is like Registry.SetConfig, bound to ctx.
+	SetConfigContext(ctx context.Context, config *Config) (*Config, error)
+
+	// ConfigContext is like Registry.Config, bound to ctx.
+	ConfigContext(ctx context.Context) (*Config, error)
+
+	// SetSubjectConfigContext is like Registry.SetSubjectConfig, bound to ctx.
+	SetSubjectConfigContext(ctx context.Context, subject string, config *Config) (*Config, error)
+
+	// SubjectConfigContext is like Registry.SubjectConfig, bound to ctx.
+	SubjectConfigContext(ctx context.Context, subject string) (*Config, error)
+
+	// DeleteSubjectConfigContext is like Registry.DeleteSubjectConfig, bound to ctx.
+	DeleteSubjectConfigContext(ctx context.Context, subject string) (*Config, error)
+
+	// SetModeContext is like Registry.SetMode, bound to ctx.
+	SetModeContext(ctx context.Context, mode Mode, opts ...ModeOption) (Mode, error)
+
+	// ModeContext is like Registry.Mode, bound to ctx.
+	ModeContext(ctx context.Context) (Mode, error)
+
+	// SetSubjectModeContext is like Registry.SetSubjectMode, bound to ctx.
+	SetSubjectModeContext(ctx context.Context, subject string, mode Mode, opts ...ModeOption) (Mode, error)
+
+	// SubjectModeContext is like Registry.SubjectMode, bound to ctx.
+	SubjectModeContext(ctx context.Context, subject string) (Mode, error)
+
+	// DeleteSubjectModeContext is like Registry.DeleteSubjectMode, bound to ctx.
+	DeleteSubjectModeContext(ctx context.Context, subject string) (Mode, error)
+}
+
+// ListOption configures the listing operations Subjects and SubjectVersions of Registry.
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	deleted       bool
+	subjectPrefix string
 }
 
-// New returns the default Registry implementation.
-func New(endpoint string) (Registry, error) {
-	_, err := url.ParseRequestURI(endpoint)
+func (o *listOptions) query() url.Values {
+	q := url.Values{}
+	if o.deleted {
+		q.Set("deleted", "true")
+	}
+	if o.subjectPrefix != "" {
+		q.Set("subjectPrefix", o.subjectPrefix)
+	}
+	return q
+}
+
+func newListOptions(opts []ListOption) *listOptions {
+	o := &listOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// IncludeDeleted makes Subjects and SubjectVersions include soft-deleted subjects and versions.
+func IncludeDeleted() ListOption {
+	return func(o *listOptions) {
+		o.deleted = true
+	}
+}
+
+// SubjectPrefix makes Subjects return only the subjects starting with prefix. It's ignored by SubjectVersions.
+func SubjectPrefix(prefix string) ListOption {
+	return func(o *listOptions) {
+		o.subjectPrefix = prefix
+	}
+}
+
+// SchemaOption configures the schema given to the operations RegisterSubjectSchema, CheckSubjectSchema,
+// TestCompatibility and TestCompatibilityAll of Registry.
+type SchemaOption func(*schemaJSON)
+
+// WithSchemaType sets the type of the schema. Without it, schemas are Avro.
+func WithSchemaType(schemaType SchemaType) SchemaOption {
+	return func(msg *schemaJSON) {
+		msg.SchemaType = schemaType
+	}
+}
+
+// WithReferences sets the references of the schema to other schemas.
+func WithReferences(references ...SchemaReference) SchemaOption {
+	return func(msg *schemaJSON) {
+		msg.References = references
+	}
+}
+
+// WithID sets the id of the registered schema, instead of letting the registry assign one. Only registries (or
+// subjects) in Import mode accept it.
+func WithID(id int) SchemaOption {
+	return func(msg *schemaJSON) {
+		msg.ID = id
+	}
+}
+
+// WithVersion sets the version of the registered schema in the subject, instead of the next one. Only registries (or
+// subjects) in Import mode accept it.
+func WithVersion(version int) SchemaOption {
+	return func(msg *schemaJSON) {
+		msg.Version = version
+	}
+}
+
+// newSchemaJSON returns the request message of an operation taking schema.
+func newSchemaJSON(schema string, opts []SchemaOption) *schemaJSON {
+	msg := &schemaJSON{Schema: schema}
+	for _, opt := range opts {
+		opt(msg)
+	}
+	return msg
+}
+
+// Option configures the Registry returned by New.
+type Option func(*registry) error
+
+// WithHTTPClient makes the Registry use client for all the requests, instead of http.DefaultClient. Use it to supply
+// a custom transport, proxy, etc.
+func WithHTTPClient(client *http.Client) Option {
+	return func(r *registry) error {
+		if client == nil {
+			return errors.New("nil HTTP client")
+		}
+		r.client = client
+		return nil
+	}
+}
+
+// WithTimeout sets a timeout for every operation, including its retries. It's applied on top of the deadline of the
+// context of the operation, if any. See RetryPolicy.PerAttemptTimeout for a timeout of each request.
+func WithTimeout(timeout time.Duration) Option {
+	return func(r *registry) error {
+		if timeout <= 0 {
+			return errors.Errorf("invalid timeout: %s", timeout)
+		}
+		r.timeout = timeout
+		return nil
+	}
+}
+
+// WithUserAgent sets the User-Agent header of every request.
+func WithUserAgent(userAgent string) Option {
+	return func(r *registry) error {
+		r.userAgent = userAgent
+		return nil
+	}
+}
+
+// New returns the default Registry implementation. The returned Registry also implements ContextRegistry.
+//
+// endpoint is the URL of the registry, optionally with a path (e.g. when the registry is behind a gateway). Several
+// comma-separated URLs can be given for a registry cluster, like "http://sr1:8081,http://sr2:8081": requests go to
+// the first healthy endpoint, failing over to the others on connection errors and 5xx responses (see
+// WithEndpointCooldown).
+func New(endpoint string, opts ...Option) (Registry, error) {
+	return newRegistry(endpoint, opts)
+}
+
+// NewContextRegistry is like New, but returns the ContextRegistry interface.
+func NewContextRegistry(endpoint string, opts ...Option) (ContextRegistry, error) {
+	return newRegistry(endpoint, opts)
+}
+
+func newRegistry(endpoint string, opts []Option) (*registry, error) {
+	endpoints, err := parseEndpoints(endpoint)
 	if err != nil {
-		return nil, errors.Wrapf(err, "invalid endpoint URL: %s", endpoint)
+		return nil, err
 	}
 	r := &registry{
-		endpoint: endpoint,
+		endpoints: endpoints,
+		cooldown:  DefaultEndpointCooldown,
+		client:    http.DefaultClient,
+	}
+	for _, opt := range opts {
+		err = opt(r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if r.tls != nil {
+		r.client, err = r.tls.httpClient(r.client)
+		if err != nil {
+			return nil, err
+		}
 	}
 	return r, nil
 }
@@ -182,7 +796,11 @@ func New(endpoint string) (Registry, error) {
 var ErrNotImplemented = errors.New("Not implemented yet :(")
 
 type schemaJSON struct {
-	Schema string `json:"schema"`
+	Schema     string            `json:"schema"`
+	SchemaType SchemaType        `json:"schemaType,omitempty"`
+	References []SchemaReference `json:"references,omitempty"`
+	ID         int               `json:"id,omitempty"`
+	Version    int               `json:"version,omitempty"`
 }
 
 type schemaIDJSON struct {
@@ -190,109 +808,608 @@ type schemaIDJSON struct {
 }
 
 type registry struct {
-	endpoint string
+	// endpoints are the registry nodes, in order of preference
+	endpoints []*endpoint
+	cooldown  time.Duration
+	client    *http.Client
+
+	// tls configures the transport of client, if not nil
+	tls *TLSConfig
+
+	// credentials authenticates the requests, overriding the credentials of the endpoint URLs. It may be nil.
+	credentials CredentialsProvider
+
+	timeout   time.Duration
+	userAgent string
+	retry     RetryPolicy
+
+	// schemas caches the schemas by id (int -> *SubjectSchema, without subject and version). A schema id never
+	// changes its schema, so entries are never invalidated.
+	schemas sync.Map
+
+	// flights coalesces the concurrent identical lookups (Schema, CheckSubjectSchema and SubjectVersion)
+	flights flightGroup
 }
 
 func (r *registry) Schema(id int) (string, error) {
-	return "", ErrNotImplemented
+	return r.SchemaContext(context.Background(), id)
 }
 
-func (r *registry) Subjects() ([]string, error) {
-	return nil, ErrNotImplemented
+func (r *registry) SchemaContext(ctx context.Context, id int) (string, error) {
+	ss, err := r.SchemaByIDContext(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return ss.Schema, nil
 }
 
-func (r *registry) SubjectVersions(subject string) ([]int, error) {
-	return nil, ErrNotImplemented
+func (r *registry) SchemaByID(id int) (*SubjectSchema, error) {
+	return r.SchemaByIDContext(context.Background(), id)
 }
 
-func (r *registry) SubjectVersion(subject string, version int) (string, error) {
-	return "", ErrNotImplemented
+func (r *registry) SchemaByIDContext(ctx context.Context, id int) (*SubjectSchema, error) {
+	if ss, ok := r.schemas.Load(id); ok {
+		return copySubjectSchema(ss.(*SubjectSchema)), nil
+	}
+
+	ss, err := r.flights.do(ctx, "schema:"+strconv.Itoa(id), func(ctx context.Context) (interface{}, error) {
+		var ss SubjectSchema
+		err := r.get(ctx, operationPath("schemas", "ids", strconv.Itoa(id)), &ss)
+		if err != nil {
+			return nil, err
+		}
+		ss.ID = id
+		r.schemas.Store(id, &ss)
+		return &ss, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return copySubjectSchema(ss.(*SubjectSchema)), nil
 }
 
-func (r *registry) RegisterSubjectSchema(subject string, schema string) (int, error) {
-	msg := schemaJSON{
-		Schema: schema,
+func (r *registry) SchemaTypes() ([]SchemaType, error) {
+	return r.SchemaTypesContext(context.Background())
+}
+
+func (r *registry) SchemaTypesContext(ctx context.Context) ([]SchemaType, error) {
+	var schemaTypes []SchemaType
+	err := r.get(ctx, operationPath("schemas", "types"), &schemaTypes)
+	if err != nil {
+		return nil, err
 	}
-	var buf bytes.Buffer
-	err := json.NewEncoder(&buf).Encode(&msg)
+	return schemaTypes, nil
+}
+
+func (r *registry) Subjects(opts ...ListOption) ([]string, error) {
+	return r.SubjectsContext(context.Background(), opts...)
+}
+
+func (r *registry) SubjectsContext(ctx context.Context, opts ...ListOption) ([]string, error) {
+	path := operationPath("subjects") + encodeQuery(newListOptions(opts).query())
+	var subjects []string
+	err := r.get(ctx, path, &subjects)
 	if err != nil {
-		return 0, errors.Wrap(err, "error creating JSON msg in RegisterSubjectSchema")
+		return nil, err
 	}
+	return subjects, nil
+}
 
-	operationURL := r.endpoint + "/subjects/" + subject + "/versions"
-	resp, err := http.Post(operationURL, "application/vnd.schemaregistry.v1+json", &buf)
+func (r *registry) SubjectVersions(subject string, opts ...ListOption) ([]int, error) {
+	return r.SubjectVersionsContext(context.Background(), subject, opts...)
+}
+
+func (r *registry) SubjectVersionsContext(ctx context.Context, subject string, opts ...ListOption) ([]int, error) {
+	path := operationPath("subjects", subject, "versions") + encodeQuery(newListOptions(opts).query())
+	var versions []int
+	err := r.get(ctx, path, &versions)
 	if err != nil {
-		return 0, errors.Wrapf(err, "error in POST %s", operationURL)
+		return nil, err
 	}
+	return versions, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		var errMsg APIError
-		err = json.NewDecoder(resp.Body).Decode(&errMsg)
+func (r *registry) SubjectVersion(subject string, version int) (*SubjectSchema, error) {
+	return r.SubjectVersionContext(context.Background(), subject, version)
+}
+
+func (r *registry) SubjectVersionContext(ctx context.Context, subject string, version int) (*SubjectSchema, error) {
+	path := operationPath("subjects", subject, "versions", versionSegment(version))
+	ss, err := r.flights.do(ctx, "version:"+path, func(ctx context.Context) (interface{}, error) {
+		var ss SubjectSchema
+		err := r.get(ctx, path, &ss)
 		if err != nil {
-			err = errors.Wrapf(err, "error decoding error response, status=%d", resp.StatusCode)
-			return 0, err
+			return nil, err
 		}
-		return 0, &errMsg
+		return &ss, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return copySubjectSchema(ss.(*SubjectSchema)), nil
+}
 
-	var respMsg schemaIDJSON
-	err = json.NewDecoder(resp.Body).Decode(&respMsg)
+func (r *registry) ReferencedBy(subject string, version int) ([]int, error) {
+	return r.ReferencedByContext(context.Background(), subject, version)
+}
+
+func (r *registry) ReferencedByContext(ctx context.Context, subject string, version int) ([]int, error) {
+	path := operationPath("subjects", subject, "versions", versionSegment(version), "referencedby")
+	var ids []int
+	err := r.get(ctx, path, &ids)
 	if err != nil {
-		return 0, errors.Wrap(err, "error decoding response in RegisterSubjectSchema")
+		return nil, err
 	}
-	return respMsg.ID, nil
+	return ids, nil
 }
 
-func (r *registry) CheckSubjectSchema(subject string, schema string) (*SubjectSchema, error) {
-	msg := schemaJSON{
-		Schema: schema,
+func (r *registry) DeleteSubject(subject string, opts ...DeleteOption) ([]int, error) {
+	return r.DeleteSubjectContext(context.Background(), subject, opts...)
+}
+
+func (r *registry) DeleteSubjectContext(ctx context.Context, subject string, opts ...DeleteOption) ([]int, error) {
+	o := newDeleteOptions(opts)
+	path := operationPath("subjects", subject) + encodeQuery(o.query())
+	var versions []int
+	err := r.do(ctx, http.MethodDelete, path, nil, &versions)
+	if err != nil {
+		return nil, deleteError(err, subject, 0, true, o.permanent)
 	}
-	var buf bytes.Buffer
-	err := json.NewEncoder(&buf).Encode(&msg)
+	return versions, nil
+}
+
+func (r *registry) DeleteSubjectVersion(subject string, version int, opts ...DeleteOption) (int, error) {
+	return r.DeleteSubjectVersionContext(context.Background(), subject, version, opts...)
+}
+
+func (r *registry) DeleteSubjectVersionContext(ctx context.Context, subject string, version int,
+	opts ...DeleteOption) (int, error) {
+	o := newDeleteOptions(opts)
+	path := operationPath("subjects", subject, "versions", versionSegment(version)) + encodeQuery(o.query())
+	var deleted int
+	err := r.do(ctx, http.MethodDelete, path, nil, &deleted)
 	if err != nil {
-		return nil, errors.Wrap(err, "error creating JSON msg in CheckSubjectSchema")
+		return 0, deleteError(err, subject, version, false, o.permanent)
 	}
+	return deleted, nil
+}
 
-	operationURL := r.endpoint + "/subjects/" + subject
-	resp, err := http.Post(operationURL, "application/vnd.schemaregistry.v1+json", &buf)
+// get issues a GET to the operation path, decoding the JSON response in v.
+func (r *registry) get(ctx context.Context, path string, v interface{}) error {
+	return r.do(ctx, http.MethodGet, path, nil, v)
+}
+
+// post issues a POST to the operation path with msg encoded as JSON, decoding the JSON response in v.
+func (r *registry) post(ctx context.Context, path string, msg interface{}, v interface{}) error {
+	return r.do(ctx, http.MethodPost, path, msg, v)
+}
+
+// do issues a request to the operation path, with msg encoded as JSON as body (if not nil), decoding the JSON
+// response in v. This is the request pipeline used by all the operations: the response body is always drained and
+// closed, so the connection can be reused, and any non-200 response is returned as an *APIError. The request fails
+// over between the endpoints, and failed attempts are retried as per the retry policy, if the request is
+// idempotent and the error is retryable.
+func (r *registry) do(ctx context.Context, method string, path string, msg interface{}, v interface{}) error {
+	var body []byte
+	if msg != nil {
+		var err error
+		body, err = json.Marshal(msg)
+		if err != nil {
+			return errors.Wrapf(err, "error creating JSON msg for %s %s", method, path)
+		}
+	}
+
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	attempts := 1
+	if idempotent(method) && r.retry.MaxAttempts > 1 {
+		attempts = r.retry.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		err := r.failover(ctx, method, path, body, v)
+		if err == nil || attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+		// with ctx alive, a deadline error means that the attempt timed out
+		if !IsRetryable(err) && !errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+		if !sleep(ctx, r.retry.backoff(attempt)) {
+			return err
+		}
+	}
+}
+
+// attempt issues a single request of do to the endpoint e. If the registry rejects the credentials (401 response)
+// and the CredentialsProvider is a CredentialsInvalidator, the credentials are invalidated and the request is
+// repeated once with fresh ones.
+func (r *registry) attempt(ctx context.Context, method string, e *endpoint, path string, body []byte,
+	v interface{}) error {
+	operationURL := e.url + path
+	resp, credentials, err := r.roundTrip(ctx, method, e, operationURL, body)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error in POST %s", operationURL)
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && credentials != nil {
+		if invalidator, ok := r.credentialsProvider(e).(CredentialsInvalidator); ok {
+			closeBody(resp.Body)
+			invalidator.InvalidateCredentials(credentials)
+			resp, _, err = r.roundTrip(ctx, method, e, operationURL, body)
+			if err != nil {
+				return err
+			}
+		}
 	}
+	defer closeBody(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
-		var errMsg APIError
-		err = json.NewDecoder(resp.Body).Decode(&errMsg)
+		return decodeError(resp)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err == io.EOF {
+		// not a connection closed by the server, so it isn't retryable like a transport io.EOF
+		return errors.Errorf("error decoding response of %s %s: empty body", method, operationURL)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "error decoding response of %s %s", method, operationURL)
+	}
+	return nil
+}
+
+// roundTrip sends an authenticated request to operationURL, returning the response and the credentials used (if
+// any).
+func (r *registry) roundTrip(ctx context.Context, method string, e *endpoint, operationURL string,
+	body []byte) (*http.Response, *Credentials, error) {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, operationURL, bodyReader)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error creating request %s %s", method, operationURL)
+	}
+	req.Header.Set("Accept", acceptHeader)
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	credentials, err := r.authenticate(ctx, e, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := r.send(ctx, req)
+	if err != nil {
+		return nil, nil, errors.Wrapf(err, "error in %s %s", method, operationURL)
+	}
+	return resp, credentials, nil
+}
+
+const (
+	// contentType is the content type of the request bodies.
+	contentType = "application/vnd.schemaregistry.v1+json"
+
+	// acceptHeader prefers the versioned registry content type, falling back to plain JSON.
+	acceptHeader = "application/vnd.schemaregistry.v1+json, application/vnd.schemaregistry+json; qs=0.9, " +
+		"application/json; qs=0.5"
+
+	// maxErrorBody is the max number of bytes read from an error response.
+	maxErrorBody = 64 << 10
+
+	// maxErrorSnippet is the max number of bytes of a non-JSON error response kept in APIError.Body.
+	maxErrorSnippet = 512
+
+	// maxDrain is the max number of unread bytes discarded before closing a response body. Bigger bodies are just
+	// closed, giving up on reusing the connection.
+	maxDrain = 64 << 10
+)
+
+// decodeError decodes the *APIError of a non-200 response. If the body isn't a JSON error message (e.g. an HTML
+// page of a proxy), the returned error has the HTTP status as code and a snippet of the body. Operations rejected by
+// the mode of the registry are returned as a *ModeError.
+func decodeError(resp *http.Response) error {
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil {
+		return errors.Wrapf(err, "error reading error response, status=%d", resp.StatusCode)
+	}
+
+	var apiErr APIError
+	err = json.Unmarshal(data, &apiErr)
+	if err == nil && apiErr.Code != 0 {
+		apiErr.StatusCode = resp.StatusCode
+		if apiErr.Code == OperationNotPermitted {
+			return &ModeError{APIError: &apiErr}
+		}
+		return &apiErr
+	}
+
+	snippet := strings.TrimSpace(string(data))
+	if len(snippet) > maxErrorSnippet {
+		snippet = snippet[:maxErrorSnippet]
+	}
+	return &APIError{
+		Code:       ErrorCode(resp.StatusCode),
+		Message:    http.StatusText(resp.StatusCode),
+		StatusCode: resp.StatusCode,
+		Body:       snippet,
+	}
+}
+
+// closeBody drains (up to maxDrain bytes) and closes a response body.
+func closeBody(body io.ReadCloser) {
+	io.CopyN(io.Discard, body, maxDrain)
+	body.Close()
+}
+
+// send sends req with the client of the registry, bound to ctx and to the per-attempt timeout of the retry policy.
+// The response body must be fully read before the returned response is closed, so the timeout is released when the
+// body is closed.
+func (r *registry) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	cancel := func() {}
+	if r.retry.PerAttemptTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, r.retry.PerAttemptTimeout)
+	}
+	if r.userAgent != "" {
+		req.Header.Set("User-Agent", r.userAgent)
+	}
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody is a response body that releases the context of its request when closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// operationPath returns the path of an operation, relative to the endpoint, joining the path segments. Each segment
+// is escaped, so subjects with "/", "%", spaces, etc. are safe.
+func operationPath(segments ...string) string {
+	var buf bytes.Buffer
+	for _, segment := range segments {
+		buf.WriteByte('/')
+		buf.WriteString(url.PathEscape(segment))
+	}
+	return buf.String()
+}
+
+// versionSegment formats a version as a path segment, mapping Latest to "latest".
+func versionSegment(version int) string {
+	if version == Latest {
+		return "latest"
+	}
+	return strconv.Itoa(version)
+}
+
+// encodeQuery returns the encoded query string (with the leading '?'), or an empty string if there are no values.
+func encodeQuery(q url.Values) string {
+	if len(q) == 0 {
+		return ""
+	}
+	return "?" + q.Encode()
+}
+
+func (r *registry) RegisterSubjectSchema(subject string, schema string, opts ...SchemaOption) (int, error) {
+	return r.RegisterSubjectSchemaContext(context.Background(), subject, schema, opts...)
+}
+
+func (r *registry) RegisterSubjectSchemaContext(ctx context.Context, subject string, schema string,
+	opts ...SchemaOption) (int, error) {
+	path := operationPath("subjects", subject, "versions")
+	msg := newSchemaJSON(schema, opts)
+	var respMsg schemaIDJSON
+	err := r.post(ctx, path, msg, &respMsg)
+	if err != nil {
+		return 0, schemaError(err, msg.SchemaType)
+	}
+	return respMsg.ID, nil
+}
+
+func (r *registry) CheckSubjectSchema(subject string, schema string, opts ...SchemaOption) (*SubjectSchema, error) {
+	return r.CheckSubjectSchemaContext(context.Background(), subject, schema, opts...)
+}
+
+func (r *registry) CheckSubjectSchemaContext(ctx context.Context, subject string, schema string,
+	opts ...SchemaOption) (*SubjectSchema, error) {
+	path := operationPath("subjects", subject)
+	msg := newSchemaJSON(schema, opts)
+	key, err := json.Marshal(msg)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating JSON msg for POST %s", path)
+	}
+	ss, err := r.flights.do(ctx, "check:"+path+"\x00"+string(key), func(ctx context.Context) (interface{},
+		error) {
+		var ss SubjectSchema
+		err := r.post(ctx, path, msg, &ss)
 		if err != nil {
-			err = errors.Wrapf(err, "error decoding error response, status=%d", resp.StatusCode)
 			return nil, err
 		}
-		return nil, &errMsg
+		return &ss, nil
+	})
+	if err != nil {
+		return nil, schemaError(err, msg.SchemaType)
 	}
+	return copySubjectSchema(ss.(*SubjectSchema)), nil
+}
 
-	var ss SubjectSchema
-	err = json.NewDecoder(resp.Body).Decode(&ss)
+func (r *registry) TestCompatibility(subject string, version int, schema string, opts ...SchemaOption) (bool,
+	error) {
+	return r.TestCompatibilityContext(context.Background(), subject, version, schema, opts...)
+}
+
+func (r *registry) TestCompatibilityContext(ctx context.Context, subject string, version int, schema string,
+	opts ...SchemaOption) (bool, error) {
+	path := operationPath("compatibility", "subjects", subject, "versions", versionSegment(version))
+	msg := newSchemaJSON(schema, opts)
+	var result CompatibilityResult
+	err := r.post(ctx, path, msg, &result)
 	if err != nil {
-		return nil, errors.Wrap(err, "error decoding response in CheckSubjectSchema")
+		return false, schemaError(err, msg.SchemaType)
 	}
-	return &ss, nil
+	return result.IsCompatible, nil
+}
+
+func (r *registry) TestCompatibilityAll(subject string, schema string, opts ...SchemaOption) (*CompatibilityResult,
+	error) {
+	return r.TestCompatibilityAllContext(context.Background(), subject, schema, opts...)
 }
 
-func (r *registry) TestCompatibility(subject string, version int, schema string) (bool, error) {
-	return false, ErrNotImplemented
+func (r *registry) TestCompatibilityAllContext(ctx context.Context, subject string, schema string,
+	opts ...SchemaOption) (*CompatibilityResult, error) {
+	path := operationPath("compatibility", "subjects", subject, "versions") + "?verbose=true"
+	msg := newSchemaJSON(schema, opts)
+	var result CompatibilityResult
+	err := r.post(ctx, path, msg, &result)
+	if err != nil {
+		return nil, schemaError(err, msg.SchemaType)
+	}
+	return &result, nil
 }
 
 func (r *registry) SetConfig(config *Config) (*Config, error) {
-	return nil, ErrNotImplemented
+	return r.SetConfigContext(context.Background(), config)
+}
+
+func (r *registry) SetConfigContext(ctx context.Context, config *Config) (*Config, error) {
+	var updated Config
+	err := r.do(ctx, http.MethodPut, operationPath("config"), config, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
 }
 
 func (r *registry) Config() (*Config, error) {
-	return nil, ErrNotImplemented
+	return r.ConfigContext(context.Background())
+}
+
+func (r *registry) ConfigContext(ctx context.Context) (*Config, error) {
+	var config Config
+	err := r.get(ctx, operationPath("config"), &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
 }
 
 func (r *registry) SetSubjectConfig(subject string, config *Config) (*Config, error) {
-	return nil, ErrNotImplemented
+	return r.SetSubjectConfigContext(context.Background(), subject, config)
+}
+
+func (r *registry) SetSubjectConfigContext(ctx context.Context, subject string, config *Config) (*Config, error) {
+	var updated Config
+	err := r.do(ctx, http.MethodPut, operationPath("config", subject), config, &updated)
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
 }
 
 func (r *registry) SubjectConfig(subject string) (*Config, error) {
-	return nil, ErrNotImplemented
+	return r.SubjectConfigContext(context.Background(), subject)
+}
+
+func (r *registry) SubjectConfigContext(ctx context.Context, subject string) (*Config, error) {
+	var config Config
+	err := r.get(ctx, operationPath("config", subject), &config)
+	if err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (r *registry) DeleteSubjectConfig(subject string) (*Config, error) {
+	return r.DeleteSubjectConfigContext(context.Background(), subject)
+}
+
+func (r *registry) DeleteSubjectConfigContext(ctx context.Context, subject string) (*Config, error) {
+	var deleted Config
+	err := r.do(ctx, http.MethodDelete, operationPath("config", subject), nil, &deleted)
+	if err != nil {
+		return nil, err
+	}
+	return &deleted, nil
+}
+
+func (r *registry) SetMode(mode Mode, opts ...ModeOption) (Mode, error) {
+	return r.SetModeContext(context.Background(), mode, opts...)
+}
+
+func (r *registry) SetModeContext(ctx context.Context, mode Mode, opts ...ModeOption) (Mode, error) {
+	path := operationPath("mode") + encodeQuery(newModeOptions(opts).query())
+	var updated modeJSON
+	err := r.do(ctx, http.MethodPut, path, &modeJSON{Mode: mode}, &updated)
+	if err != nil {
+		return 0, err
+	}
+	return updated.Mode, nil
+}
+
+func (r *registry) Mode() (Mode, error) {
+	return r.ModeContext(context.Background())
+}
+
+func (r *registry) ModeContext(ctx context.Context) (Mode, error) {
+	var mode modeJSON
+	err := r.get(ctx, operationPath("mode"), &mode)
+	if err != nil {
+		return 0, err
+	}
+	return mode.Mode, nil
+}
+
+func (r *registry) SetSubjectMode(subject string, mode Mode, opts ...ModeOption) (Mode, error) {
+	return r.SetSubjectModeContext(context.Background(), subject, mode, opts...)
+}
+
+func (r *registry) SetSubjectModeContext(ctx context.Context, subject string, mode Mode,
+	opts ...ModeOption) (Mode, error) {
+	path := operationPath("mode", subject) + encodeQuery(newModeOptions(opts).query())
+	var updated modeJSON
+	err := r.do(ctx, http.MethodPut, path, &modeJSON{Mode: mode}, &updated)
+	if err != nil {
+		return 0, err
+	}
+	return updated.Mode, nil
+}
+
+func (r *registry) SubjectMode(subject string) (Mode, error) {
+	return r.SubjectModeContext(context.Background(), subject)
+}
+
+func (r *registry) SubjectModeContext(ctx context.Context, subject string) (Mode, error) {
+	var mode modeJSON
+	err := r.get(ctx, operationPath("mode", subject), &mode)
+	if err != nil {
+		return 0, err
+	}
+	return mode.Mode, nil
+}
+
+func (r *registry) DeleteSubjectMode(subject string) (Mode, error) {
+	return r.DeleteSubjectModeContext(context.Background(), subject)
+}
+
+func (r *registry) DeleteSubjectModeContext(ctx context.Context, subject string) (Mode, error) {
+	var deleted modeJSON
+	err := r.do(ctx, http.MethodDelete, operationPath("mode", subject), nil, &deleted)
+	if err != nil {
+		return 0, err
+	}
+	return deleted.Mode, nil
 }
@@ -0,0 +1,78 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_SchemaOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/schemas/ids/1", r.URL.String())
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"schema": testSchema})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	schema, err := registry.Schema(1)
+	require.Nil(t, err)
+	assert.Equal(t, testSchema, schema)
+}
+
+func TestRegistry_SchemaCached(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{"schema": testSchema})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	for i := 0; i < 3; i++ {
+		schema, err := registry.Schema(1)
+		require.Nil(t, err)
+		assert.Equal(t, testSchema, schema)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_SchemaErrSchemaNotFound(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.SchemaNotFound,
+			Message: "Schema not found",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = registry.Schema(1)
+		apiErr, ok := err.(*schemaregistry.APIError)
+		require.True(t, ok)
+		assert.Equal(t, schemaregistry.SchemaNotFound, apiErr.Code)
+		assert.Equal(t, "Schema not found", apiErr.Message)
+	}
+	// errors are never cached
+	assert.EqualValues(t, 2, atomic.LoadInt32(&calls))
+}
@@ -0,0 +1,184 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testProtoSchema = `syntax = "proto3";
+package com.example;
+
+message Frame {
+  bytes data = 1;
+}
+`
+
+func TestSchemaType_Text(t *testing.T) {
+	data, err := json.Marshal([]schemaregistry.SchemaType{schemaregistry.Avro, schemaregistry.Protobuf,
+		schemaregistry.JSON})
+	require.Nil(t, err)
+	assert.Equal(t, `["AVRO","PROTOBUF","JSON"]`, string(data))
+
+	var schemaTypes []schemaregistry.SchemaType
+	require.Nil(t, json.Unmarshal([]byte(`["JSON","protobuf","AVRO"]`), &schemaTypes))
+	assert.Equal(t, []schemaregistry.SchemaType{schemaregistry.JSON, schemaregistry.Protobuf, schemaregistry.Avro},
+		schemaTypes)
+
+	assert.Error(t, json.Unmarshal([]byte(`["XML"]`), &schemaTypes))
+	assert.Equal(t, "Protobuf", schemaregistry.Protobuf.String())
+}
+
+func TestRegistry_SchemaTypes(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/schemas/types", r.URL.String())
+		w.Write([]byte(`["JSON","PROTOBUF","AVRO"]`))
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	schemaTypes, err := registry.SchemaTypes()
+	require.Nil(t, err)
+	assert.Equal(t, []schemaregistry.SchemaType{schemaregistry.JSON, schemaregistry.Protobuf, schemaregistry.Avro},
+		schemaTypes)
+}
+
+func TestRegistry_RegisterSubjectSchemaType(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]interface{}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&msg))
+		switch msg["schema"] {
+		case testProtoSchema:
+			assert.Equal(t, "PROTOBUF", msg["schemaType"])
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 2})
+		default:
+			// Avro schemas are sent without type
+			assert.NotContains(t, msg, "schemaType")
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 1})
+		}
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	id, err := registry.RegisterSubjectSchema("frames-value", testProtoSchema,
+		schemaregistry.WithSchemaType(schemaregistry.Protobuf))
+	require.Nil(t, err)
+	assert.Equal(t, 2, id)
+
+	id, err = registry.RegisterSubjectSchema("frames-value", testSchema)
+	require.Nil(t, err)
+	assert.Equal(t, 1, id)
+}
+
+func TestRegistry_CheckSubjectSchemaType(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]interface{}
+		require.Nil(t, json.NewDecoder(r.Body).Decode(&msg))
+		assert.Equal(t, "PROTOBUF", msg["schemaType"])
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"subject":    "frames-value",
+			"id":         2,
+			"version":    1,
+			"schema":     testProtoSchema,
+			"schemaType": "PROTOBUF",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	ss, err := registry.CheckSubjectSchema("frames-value", testProtoSchema,
+		schemaregistry.WithSchemaType(schemaregistry.Protobuf))
+	require.Nil(t, err)
+	assert.Equal(t, &schemaregistry.SubjectSchema{
+		Subject:    "frames-value",
+		ID:         2,
+		Version:    1,
+		Schema:     testProtoSchema,
+		SchemaType: schemaregistry.Protobuf,
+	}, ss)
+}
+
+func TestRegistry_SchemaByID(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/schemas/ids/2", r.URL.String())
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"schema":     testProtoSchema,
+			"schemaType": "PROTOBUF",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	ss, err := registry.SchemaByID(2)
+	require.Nil(t, err)
+	assert.Equal(t, &schemaregistry.SubjectSchema{
+		ID:         2,
+		Schema:     testProtoSchema,
+		SchemaType: schemaregistry.Protobuf,
+	}, ss)
+
+	schema, err := registry.Schema(2)
+	require.Nil(t, err)
+	assert.Equal(t, testProtoSchema, schema)
+}
+
+func TestRegistry_InvalidSchemaError(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.InvalidSchema,
+			Message: "Invalid schema: missing message",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	protobuf := schemaregistry.WithSchemaType(schemaregistry.Protobuf)
+	_, err = registry.RegisterSubjectSchema("frames-value", testProtoSchema, protobuf)
+	assertInvalidSchema(t, err, schemaregistry.Protobuf)
+
+	_, err = registry.CheckSubjectSchema("frames-value", testProtoSchema, protobuf)
+	assertInvalidSchema(t, err, schemaregistry.Protobuf)
+
+	_, err = registry.TestCompatibility("frames-value", schemaregistry.Latest, `{}`,
+		schemaregistry.WithSchemaType(schemaregistry.JSON))
+	assertInvalidSchema(t, err, schemaregistry.JSON)
+
+	_, err = registry.TestCompatibilityAll("frames-value", testSchema)
+	assertInvalidSchema(t, err, schemaregistry.Avro)
+}
+
+func assertInvalidSchema(t *testing.T, err error, schemaType schemaregistry.SchemaType) {
+	var schemaErr *schemaregistry.InvalidSchemaError
+	require.True(t, errors.As(err, &schemaErr))
+	assert.Equal(t, schemaType, schemaErr.SchemaType)
+	assert.Contains(t, err.Error(), "invalid "+schemaType.String()+" schema")
+
+	// it's also an *APIError
+	var apiErr *schemaregistry.APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, schemaregistry.InvalidSchema, apiErr.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+}
@@ -0,0 +1,90 @@
+package schemaregistry
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup coalesces concurrent calls with the same key: while a call is in flight, the other callers with the
+// same key wait for it and share its result, instead of issuing their own call.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done     chan struct{}
+	val      interface{}
+	err      error
+	panicked interface{} // the value fn panicked with, if any
+
+	// waiters is the number of callers waiting for the call (guarded by the mutex of the group). The call is
+	// cancelled when all of them give up.
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do calls fn, unless there is a call in flight for key, in which case it waits for it and returns its result.
+//
+// The call is shared, so it runs with a context detached from the cancellation of ctx (keeping its values): a caller
+// giving up doesn't fail the others. Each caller waits for the result until its own ctx is done, and the call is
+// cancelled when all the callers have given up. If fn panics, the panic is propagated to the callers.
+func (g *flightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (interface{},
+	error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, ok := g.calls[key]
+	if !ok {
+		callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		call = &flightCall{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = call
+		go g.run(callCtx, key, call, fn)
+	}
+	call.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+		if call.panicked != nil {
+			panic(call.panicked)
+		}
+		return call.val, call.err
+	case <-ctx.Done():
+		g.leave(key, call)
+		return nil, ctx.Err()
+	}
+}
+
+// leave removes a waiter that gave up on call, cancelling the call if it was the last one. The cancelled call is
+// forgotten, so later callers don't join it.
+func (g *flightGroup) leave(key string, call *flightCall) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	call.waiters--
+	if call.waiters > 0 {
+		return
+	}
+	call.cancel()
+	if g.calls[key] == call {
+		delete(g.calls, key)
+	}
+}
+
+func (g *flightGroup) run(ctx context.Context, key string, call *flightCall,
+	fn func(ctx context.Context) (interface{}, error)) {
+	defer func() {
+		if r := recover(); r != nil {
+			call.panicked = r
+		}
+		g.mu.Lock()
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+		g.mu.Unlock()
+		call.cancel()
+		close(call.done)
+	}()
+	call.val, call.err = fn(ctx)
+}
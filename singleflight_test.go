@@ -0,0 +1,229 @@
+package schemaregistry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const concurrentCallers = 50
+
+// gatedServer returns a server that holds all the requests until release is closed, counting them in calls.
+func gatedServer(calls *int32, release chan struct{}, handler http.HandlerFunc) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+		<-release
+		handler(w, r)
+	}))
+}
+
+// whenWaiting calls release once all the concurrent callers are waiting for the in-flight lookups of registry.
+func whenWaiting(registry interface{}, release func()) {
+	go func() {
+		for schemaregistry.FlightWaiters(registry) < concurrentCallers {
+			time.Sleep(time.Millisecond)
+		}
+		release()
+	}()
+}
+
+func runConcurrently(fn func()) {
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentCallers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRegistry_SchemaCoalesced(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	release := make(chan struct{})
+	ts := gatedServer(&calls, release, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"schema": testSchema})
+	})
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	whenWaiting(registry, func() { close(release) })
+	runConcurrently(func() {
+		schema, err := registry.Schema(1)
+		assert.Nil(t, err)
+		assert.Equal(t, testSchema, schema)
+	})
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_CheckSubjectSchemaCoalescedError(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	release := make(chan struct{})
+	ts := gatedServer(&calls, release, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.SchemaNotFound,
+			Message: "Schema not found",
+		})
+	})
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	whenWaiting(registry, func() { close(release) })
+	runConcurrently(func() {
+		_, err := registry.CheckSubjectSchema("frames-value", testSchema)
+		apiErr, ok := err.(*schemaregistry.APIError)
+		if assert.True(t, ok) {
+			assert.Equal(t, schemaregistry.SchemaNotFound, apiErr.Code)
+		}
+	})
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_SubjectVersionCoalesced(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	release := make(chan struct{})
+	ts := gatedServer(&calls, release, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(schemaregistry.SubjectSchema{
+			Subject: "frames-value",
+			ID:      1,
+			Version: 2,
+			Schema:  testSchema,
+		})
+	})
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	whenWaiting(registry, func() { close(release) })
+	runConcurrently(func() {
+		ss, err := registry.SubjectVersion("frames-value", schemaregistry.Latest)
+		if assert.Nil(t, err) {
+			assert.Equal(t, 2, ss.Version)
+		}
+	})
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestCachedRegistry_SubjectVersionCoalesced(t *testing.T) {
+	t.Parallel()
+	release := make(chan time.Time)
+	mock := &schemaregistry.MockRegistry{}
+	mock.On("SubjectVersion", "frames-value", 2).Return(&schemaregistry.SubjectSchema{
+		Subject: "frames-value",
+		ID:      1,
+		Version: 2,
+		Schema:  testSchema,
+	}, nil).WaitUntil(release)
+	registry := schemaregistry.NewCachedRegistry(mock)
+
+	whenWaiting(registry, func() { close(release) })
+	runConcurrently(func() {
+		ss, err := registry.SubjectVersion("frames-value", 2)
+		if assert.Nil(t, err) {
+			assert.Equal(t, 1, ss.ID)
+		}
+	})
+	mock.AssertNumberOfCalls(t, "SubjectVersion", 1)
+}
+
+func TestRegistry_CoalescedLeaderCancelled(t *testing.T) {
+	t.Parallel()
+	var calls int32
+	release := make(chan struct{})
+	ts := gatedServer(&calls, release, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"schema": testSchema})
+	})
+	defer ts.Close()
+
+	registry, err := schemaregistry.NewContextRegistry(ts.URL)
+	require.Nil(t, err)
+
+	// the leading caller gives up while the request is in flight
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error)
+	go func() {
+		_, err := registry.SchemaByIDContext(ctx, 1)
+		leaderDone <- err
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	waiterDone := make(chan error)
+	go func() {
+		ss, err := registry.SchemaByIDContext(context.Background(), 1)
+		if err == nil {
+			assert.Equal(t, testSchema, ss.Schema)
+		}
+		waiterDone <- err
+	}()
+
+	// an impatient waiter returns at its own deadline
+	deadline, cancelDeadline := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancelDeadline()
+	start := time.Now()
+	_, err = registry.SchemaByIDContext(deadline, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-leaderDone)
+
+	// the shared request isn't cancelled with the leader
+	close(release)
+	assert.Nil(t, <-waiterDone)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&calls))
+}
+
+func TestRegistry_CoalescedAllCancelled(t *testing.T) {
+	t.Parallel()
+	cancelled := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		close(cancelled)
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.NewContextRegistry(ts.URL)
+	require.Nil(t, err)
+
+	// once the only caller reaches its deadline, the shared request is cancelled
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = registry.SchemaByIDContext(ctx, 1)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("shared request not cancelled")
+	}
+}
+
+func TestCachedRegistry_CoalescedPanic(t *testing.T) {
+	t.Parallel()
+	// without expectations, the mock panics
+	registry := schemaregistry.NewCachedRegistry(&schemaregistry.MockRegistry{})
+
+	assert.Panics(t, func() { registry.Schema(1) })
+	// the panicking lookup isn't left in flight
+	assert.Panics(t, func() { registry.Schema(1) })
+}
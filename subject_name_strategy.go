@@ -0,0 +1,55 @@
+package schemaregistry
+
+import (
+	"github.com/pkg/errors"
+)
+
+// SubjectNameStrategy names the subject of the schema of the keys or values of a topic. Serializers use it to find
+// the subject where they register or look up the schemas. Implementations must be safe for concurrent use.
+type SubjectNameStrategy interface {
+	// Subject returns the subject of the keys (if isKey) or values of topic, whose schema has the fully-qualified
+	// recordName (e.g. the namespace and name of an Avro record, or the package and name of a Protobuf message).
+	Subject(topic string, isKey bool, recordName string) (string, error)
+}
+
+// SubjectNameStrategyFunc adapts a function to SubjectNameStrategy.
+type SubjectNameStrategyFunc func(topic string, isKey bool, recordName string) (string, error)
+
+// Subject calls f(topic, isKey, recordName).
+func (f SubjectNameStrategyFunc) Subject(topic string, isKey bool, recordName string) (string, error) {
+	return f(topic, isKey, recordName)
+}
+
+// ErrNoRecordName is returned by the strategies that need a record name when it's empty (e.g. for schemas of
+// primitive types).
+var ErrNoRecordName = errors.New("subject name strategy requires a record name")
+
+// TopicNameStrategy names the subjects after the topic: "<topic>-key" and "<topic>-value". It's the default strategy,
+// for topics with a single type of keys and values.
+var TopicNameStrategy SubjectNameStrategy = SubjectNameStrategyFunc(func(topic string, isKey bool,
+	recordName string) (string, error) {
+	if isKey {
+		return topic + "-key", nil
+	}
+	return topic + "-value", nil
+})
+
+// RecordNameStrategy names the subjects after the fully-qualified record name, so a topic may have several types of
+// keys or values, and the schema of a record type is shared by all the topics.
+var RecordNameStrategy SubjectNameStrategy = SubjectNameStrategyFunc(func(topic string, isKey bool,
+	recordName string) (string, error) {
+	if recordName == "" {
+		return "", ErrNoRecordName
+	}
+	return recordName, nil
+})
+
+// TopicRecordNameStrategy names the subjects "<topic>-<record name>", so a topic may have several types of keys or
+// values, with schemas independent of the other topics.
+var TopicRecordNameStrategy SubjectNameStrategy = SubjectNameStrategyFunc(func(topic string, isKey bool,
+	recordName string) (string, error) {
+	if recordName == "" {
+		return "", ErrNoRecordName
+	}
+	return topic + "-" + recordName, nil
+})
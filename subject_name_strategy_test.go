@@ -0,0 +1,41 @@
+package schemaregistry_test
+
+import (
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubjectNameStrategies(t *testing.T) {
+	tests := []struct {
+		strategy schemaregistry.SubjectNameStrategy
+		isKey    bool
+		expected string
+	}{
+		{schemaregistry.TopicNameStrategy, true, "frames-key"},
+		{schemaregistry.TopicNameStrategy, false, "frames-value"},
+		{schemaregistry.RecordNameStrategy, true, "com.example.Frame"},
+		{schemaregistry.RecordNameStrategy, false, "com.example.Frame"},
+		{schemaregistry.TopicRecordNameStrategy, true, "frames-com.example.Frame"},
+		{schemaregistry.TopicRecordNameStrategy, false, "frames-com.example.Frame"},
+	}
+	for _, test := range tests {
+		subject, err := test.strategy.Subject("frames", test.isKey, "com.example.Frame")
+		require.Nil(t, err)
+		assert.Equal(t, test.expected, subject)
+	}
+}
+
+func TestSubjectNameStrategies_NoRecordName(t *testing.T) {
+	subject, err := schemaregistry.TopicNameStrategy.Subject("frames", false, "")
+	require.Nil(t, err)
+	assert.Equal(t, "frames-value", subject)
+
+	_, err = schemaregistry.RecordNameStrategy.Subject("frames", false, "")
+	assert.Equal(t, schemaregistry.ErrNoRecordName, err)
+
+	_, err = schemaregistry.TopicRecordNameStrategy.Subject("frames", false, "")
+	assert.Equal(t, schemaregistry.ErrNoRecordName, err)
+}
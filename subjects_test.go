@@ -0,0 +1,70 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_SubjectsOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/subjects", r.URL.String())
+
+		json.NewEncoder(w).Encode([]string{"frames-key", "frames-value"})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	subjects, err := registry.Subjects()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"frames-key", "frames-value"}, subjects)
+}
+
+func TestRegistry_SubjectsWithOptions(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects", r.URL.Path)
+		assert.Equal(t, "true", r.URL.Query().Get("deleted"))
+		assert.Equal(t, "frames", r.URL.Query().Get("subjectPrefix"))
+
+		json.NewEncoder(w).Encode([]string{"frames-value"})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	subjects, err := registry.Subjects(schemaregistry.IncludeDeleted(), schemaregistry.SubjectPrefix("frames"))
+	require.Nil(t, err)
+	assert.Equal(t, []string{"frames-value"}, subjects)
+}
+
+func TestRegistry_SubjectsErrInternalServer(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.BackendStoreErr,
+			Message: "Error in the backend data store",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.Subjects()
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.Equal(t, schemaregistry.BackendStoreErr, apiErr.Code)
+	assert.Equal(t, "Error in the backend data store", apiErr.Message)
+}
@@ -0,0 +1,82 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_SubjectVersionOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/subjects/frames-value/versions/2", r.URL.String())
+
+		json.NewEncoder(w).Encode(schemaregistry.SubjectSchema{
+			Subject: "frames-value",
+			ID:      1,
+			Version: 2,
+			Schema:  testSchema,
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	ss, err := registry.SubjectVersion("frames-value", 2)
+	require.Nil(t, err)
+	assert.Equal(t, "frames-value", ss.Subject)
+	assert.Equal(t, 1, ss.ID)
+	assert.Equal(t, 2, ss.Version)
+	assert.Equal(t, testSchema, ss.Schema)
+}
+
+func TestRegistry_SubjectVersionLatest(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects/frames-value/versions/latest", r.URL.String())
+
+		json.NewEncoder(w).Encode(schemaregistry.SubjectSchema{
+			Subject: "frames-value",
+			ID:      7,
+			Version: 3,
+			Schema:  testSchema,
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	ss, err := registry.SubjectVersion("frames-value", schemaregistry.Latest)
+	require.Nil(t, err)
+	assert.Equal(t, 7, ss.ID)
+	assert.Equal(t, 3, ss.Version)
+}
+
+func TestRegistry_SubjectVersionErrVersionNotFound(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.VersionNotFound,
+			Message: "Version not found",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.SubjectVersion("frames-value", 9)
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.Equal(t, schemaregistry.VersionNotFound, apiErr.Code)
+	assert.Equal(t, "Version not found", apiErr.Message)
+}
@@ -0,0 +1,68 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_SubjectVersionsOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/subjects/frames-value/versions", r.URL.String())
+
+		json.NewEncoder(w).Encode([]int{1, 2, 3})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	versions, err := registry.SubjectVersions("frames-value")
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3}, versions)
+}
+
+func TestRegistry_SubjectVersionsIncludeDeleted(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/subjects/frames-value/versions?deleted=true", r.URL.String())
+
+		json.NewEncoder(w).Encode([]int{1, 2, 3, 4})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	versions, err := registry.SubjectVersions("frames-value", schemaregistry.IncludeDeleted())
+	require.Nil(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, versions)
+}
+
+func TestRegistry_SubjectVersionsErrSubjectNotFound(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.SubjectNotFound,
+			Message: "Subject not found",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.SubjectVersions("frames-value")
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.Equal(t, schemaregistry.SubjectNotFound, apiErr.Code)
+	assert.Equal(t, "Subject not found", apiErr.Message)
+}
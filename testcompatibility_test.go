@@ -0,0 +1,100 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_TestCompatibilityOK(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/compatibility/subjects/frames-value/versions/latest", r.URL.String())
+		assert.Equal(t, "application/vnd.schemaregistry.v1+json", r.Header.Get("Content-Type"))
+
+		var msg map[string]string
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		require.Nil(t, err)
+		assert.Equal(t, testSchema, msg["schema"])
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"is_compatible": true})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	compatible, err := registry.TestCompatibility("frames-value", schemaregistry.Latest, testSchema)
+	require.Nil(t, err)
+	assert.True(t, compatible)
+}
+
+func TestRegistry_TestCompatibilityIncompatible(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/compatibility/subjects/frames-value/versions/2", r.URL.String())
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"is_compatible": false})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	compatible, err := registry.TestCompatibility("frames-value", 2, testSchema)
+	require.Nil(t, err)
+	assert.False(t, compatible)
+}
+
+func TestRegistry_TestCompatibilityErrVersionNotFound(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(&schemaregistry.APIError{
+			Code:    schemaregistry.VersionNotFound,
+			Message: "Version not found",
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	_, err = registry.TestCompatibility("frames-value", 9, testSchema)
+	apiErr, ok := err.(*schemaregistry.APIError)
+	require.True(t, ok)
+	assert.Equal(t, schemaregistry.VersionNotFound, apiErr.Code)
+}
+
+func TestRegistry_TestCompatibilityAllVerbose(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "/compatibility/subjects/frames-value/versions?verbose=true", r.URL.String())
+
+		var msg map[string]string
+		err := json.NewDecoder(r.Body).Decode(&msg)
+		require.Nil(t, err)
+		assert.Equal(t, testSchema, msg["schema"])
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"is_compatible": false,
+			"messages":      []string{"READER_FIELD_MISSING_DEFAULT_VALUE, location:/fields/1"},
+		})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL)
+	require.Nil(t, err)
+
+	result, err := registry.TestCompatibilityAll("frames-value", testSchema)
+	require.Nil(t, err)
+	assert.False(t, result.IsCompatible)
+	assert.Equal(t, []string{"READER_FIELD_MISSING_DEFAULT_VALUE, location:/fields/1"}, result.Messages)
+}
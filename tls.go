@@ -0,0 +1,245 @@
+package schemaregistry
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TLSConfig configures the TLS connections to the registry. Certificates and keys may be given as files or PEM
+// data; files are reloaded when they change on disk, so rotated certificates are used by the new connections without
+// restarting.
+type TLSConfig struct {
+	// CAFile and CAPEM are the CA bundle used to verify the registry, as a PEM file or PEM data. If both are empty,
+	// the system roots are used.
+	CAFile string
+	CAPEM  []byte
+
+	// CertFile and KeyFile are the client certificate and key (for mutual TLS), as PEM files.
+	CertFile string
+	KeyFile  string
+
+	// CertPEM and KeyPEM are the client certificate and key, as PEM data. They're ignored if CertFile is set.
+	CertPEM []byte
+	KeyPEM  []byte
+
+	// ServerName overrides the name used to verify the certificate of the registry (and sent as SNI).
+	ServerName string
+
+	// InsecureSkipVerify disables the verification of the certificate of the registry. Use it only for development.
+	InsecureSkipVerify bool
+}
+
+// WithTLS makes the Registry use config for the TLS connections. It's applied to a clone of the transport of the
+// HTTP client (see WithHTTPClient), which must be an *http.Transport.
+func WithTLS(config TLSConfig) Option {
+	return func(r *registry) error {
+		r.tls = &config
+		return nil
+	}
+}
+
+// httpClient returns a copy of client, with a clone of its transport configured with c.
+func (c *TLSConfig) httpClient(client *http.Client) (*http.Client, error) {
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, errors.Errorf("TLS config requires an *http.Transport, got %T", client.Transport)
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	tlsClient := *client
+	tlsClient.Transport = transport
+	return &tlsClient, nil
+}
+
+func (c *TLSConfig) tlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	switch {
+	case c.CertFile != "" || c.KeyFile != "":
+		if c.CertFile == "" || c.KeyFile == "" {
+			return nil, errors.New("both CertFile and KeyFile are required")
+		}
+		certs := &reloadingFiles{
+			paths: []string{c.CertFile, c.KeyFile},
+			load: func(data [][]byte) (interface{}, error) {
+				return parseKeyPair(data[0], data[1])
+			},
+		}
+		_, err := certs.get()
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := certs.get()
+			if err != nil {
+				return nil, err
+			}
+			return cert.(*tls.Certificate), nil
+		}
+	case len(c.CertPEM) > 0 || len(c.KeyPEM) > 0:
+		if len(c.CertPEM) == 0 || len(c.KeyPEM) == 0 {
+			return nil, errors.New("both CertPEM and KeyPEM are required")
+		}
+		cert, err := parseKeyPair(c.CertPEM, c.KeyPEM)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{*cert}
+	}
+
+	switch {
+	case c.InsecureSkipVerify:
+		// nothing to verify
+	case c.CAFile != "":
+		roots := &reloadingFiles{
+			paths: []string{c.CAFile},
+			load: func(data [][]byte) (interface{}, error) {
+				return parseCertPool(data[0])
+			},
+		}
+		_, err := roots.get()
+		if err != nil {
+			return nil, err
+		}
+		// the standard verification can't reload the roots, so it's disabled and done by verifyConnection instead
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyConnection = func(cs tls.ConnectionState) error {
+			pool, err := roots.get()
+			if err != nil {
+				return err
+			}
+			return verifyConnection(cs, pool.(*x509.CertPool))
+		}
+	case len(c.CAPEM) > 0:
+		pool, err := parseCertPool(c.CAPEM)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	return tlsConfig, nil
+}
+
+// verifyConnection verifies the certificate chain of the server like crypto/tls does, with roots.
+func verifyConnection(cs tls.ConnectionState, roots *x509.CertPool) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("registry sent no TLS certificate")
+	}
+	opts := x509.VerifyOptions{
+		Roots:         roots,
+		DNSName:       cs.ServerName,
+		Intermediates: x509.NewCertPool(),
+	}
+	for _, cert := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+func parseKeyPair(certPEM []byte, keyPEM []byte) (*tls.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid client certificate")
+	}
+	return &cert, nil
+}
+
+func parseCertPool(caPEM []byte) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.New("invalid CA bundle: no PEM certificates found")
+	}
+	return pool, nil
+}
+
+// reloadingFiles is a value loaded from files, loaded again when any of them changes (by modification time or
+// size). If loading fails after a change (e.g. in the middle of a rotation, with a new certificate but the old key),
+// the last good value is kept, and the load is tried again later.
+type reloadingFiles struct {
+	paths []string
+	load  func(data [][]byte) (interface{}, error)
+
+	mu     sync.Mutex
+	stamps []fileStamp
+	value  interface{}
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func (f *reloadingFiles) get() (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	stamps := make([]fileStamp, len(f.paths))
+	for i, path := range f.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			if f.value != nil {
+				return f.value, nil
+			}
+			return nil, errors.Wrapf(err, "error reading %s", path)
+		}
+		stamps[i] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+	}
+	if f.value != nil && stampsEqual(stamps, f.stamps) {
+		return f.value, nil
+	}
+
+	data := make([][]byte, len(f.paths))
+	for i, path := range f.paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			if f.value != nil {
+				return f.value, nil
+			}
+			return nil, errors.Wrapf(err, "error reading %s", path)
+		}
+		data[i] = bytes.TrimSpace(content)
+	}
+	value, err := f.load(data)
+	if err != nil {
+		if f.value != nil {
+			return f.value, nil
+		}
+		return nil, errors.Wrapf(err, "error loading %v", f.paths)
+	}
+	f.value = value
+	f.stamps = stamps
+	return value, nil
+}
+
+func stampsEqual(a []fileStamp, b []fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].modTime.Equal(b[i].modTime) || a[i].size != b[i].size {
+			return false
+		}
+	}
+	return true
+}
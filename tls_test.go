@@ -0,0 +1,265 @@
+package schemaregistry_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA is a CA issuing test certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newTestCA(t *testing.T, name string) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	return &testCA{
+		cert: cert,
+		key:  key,
+		pem:  pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+	}
+}
+
+// issue returns a certificate and key (PEM) for name, valid for servers (with name as DNS name) and clients.
+func (ca *testCA) issue(t *testing.T, name string, ips ...net.IP) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  ips,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.Nil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.Nil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// tlsServer returns a TLS registry server with a certificate of ca for name (and 127.0.0.1), answering the common
+// name of the client certificate as the only subject. If clientCA isn't nil, client certificates of it are required.
+// Connections aren't kept alive, so every request makes a handshake.
+func tlsServer(t *testing.T, ca *testCA, name string, clientCA *testCA) *httptest.Server {
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Connection", "close")
+		subjects := []string{}
+		if len(r.TLS.PeerCertificates) > 0 {
+			subjects = append(subjects, r.TLS.PeerCertificates[0].Subject.CommonName)
+		}
+		json.NewEncoder(w).Encode(subjects)
+	}))
+	certPEM, keyPEM := ca.issue(t, name, net.ParseIP("127.0.0.1"))
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.Nil(t, err)
+	ts.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	if clientCA != nil {
+		ts.TLS.ClientAuth = tls.RequireAndVerifyClientCert
+		ts.TLS.ClientCAs = x509.NewCertPool()
+		ts.TLS.ClientCAs.AddCert(clientCA.cert)
+	}
+	ts.StartTLS()
+	return ts
+}
+
+// writeFile writes data to path, with a modification time after the previous one.
+func writeFile(t *testing.T, path string, data []byte, modTime time.Time) {
+	require.Nil(t, os.WriteFile(path, data, 0600))
+	require.Nil(t, os.Chtimes(path, modTime, modTime))
+}
+
+func TestTLS_MutualPEM(t *testing.T) {
+	t.Parallel()
+	ca := newTestCA(t, "registry-ca")
+	ts := tlsServer(t, ca, "registry.local", ca)
+	defer ts.Close()
+
+	certPEM, keyPEM := ca.issue(t, "frames-service")
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithTLS(schemaregistry.TLSConfig{
+		CAPEM:   ca.pem,
+		CertPEM: certPEM,
+		KeyPEM:  keyPEM,
+	}))
+	require.Nil(t, err)
+
+	subjects, err := registry.Subjects()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"frames-service"}, subjects)
+}
+
+func TestTLS_MissingClientCertificate(t *testing.T) {
+	t.Parallel()
+	ca := newTestCA(t, "registry-ca")
+	ts := tlsServer(t, ca, "registry.local", ca)
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithTLS(schemaregistry.TLSConfig{CAPEM: ca.pem}),
+		schemaregistry.WithRetry(schemaregistry.RetryPolicy{MaxAttempts: 1}))
+	require.Nil(t, err)
+
+	_, err = registry.Subjects()
+	assert.Error(t, err)
+}
+
+func TestTLS_UnknownCA(t *testing.T) {
+	t.Parallel()
+	ca := newTestCA(t, "registry-ca")
+	ts := tlsServer(t, ca, "registry.local", nil)
+	defer ts.Close()
+
+	other := newTestCA(t, "other-ca")
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithTLS(schemaregistry.TLSConfig{CAPEM: other.pem}),
+		schemaregistry.WithRetry(schemaregistry.RetryPolicy{MaxAttempts: 1}))
+	require.Nil(t, err)
+
+	_, err = registry.Subjects()
+	assert.Error(t, err)
+}
+
+func TestTLS_ServerName(t *testing.T) {
+	t.Parallel()
+	ca := newTestCA(t, "registry-ca")
+	ts := tlsServer(t, ca, "registry.internal", nil)
+	defer ts.Close()
+
+	// the URL has the IP of the server, the certificate is verified with the overridden name
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithTLS(schemaregistry.TLSConfig{
+		CAPEM:      ca.pem,
+		ServerName: "registry.internal",
+	}))
+	require.Nil(t, err)
+	_, err = registry.Subjects()
+	assert.Nil(t, err)
+
+	registry, err = schemaregistry.New(ts.URL, schemaregistry.WithTLS(schemaregistry.TLSConfig{
+		CAPEM:      ca.pem,
+		ServerName: "registry.external",
+	}), schemaregistry.WithRetry(schemaregistry.RetryPolicy{MaxAttempts: 1}))
+	require.Nil(t, err)
+	_, err = registry.Subjects()
+	assert.Error(t, err)
+}
+
+func TestTLS_InsecureSkipVerify(t *testing.T) {
+	t.Parallel()
+	ca := newTestCA(t, "registry-ca")
+	ts := tlsServer(t, ca, "registry.local", nil)
+	defer ts.Close()
+
+	registry, err := schemaregistry.New(ts.URL,
+		schemaregistry.WithTLS(schemaregistry.TLSConfig{InsecureSkipVerify: true}))
+	require.Nil(t, err)
+
+	_, err = registry.Subjects()
+	assert.Nil(t, err)
+}
+
+func TestTLS_ReloadFiles(t *testing.T) {
+	t.Parallel()
+	serverCA := newTestCA(t, "registry-ca")
+	clientCA := newTestCA(t, "client-ca")
+	ts := tlsServer(t, serverCA, "registry.local", clientCA)
+	defer ts.Close()
+
+	dir, err := os.MkdirTemp("", "tls-test")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	certFile := filepath.Join(dir, "client.pem")
+	keyFile := filepath.Join(dir, "client-key.pem")
+
+	modTime := time.Now().Add(-time.Hour)
+	writeFile(t, caFile, serverCA.pem, modTime)
+	certPEM, keyPEM := clientCA.issue(t, "client-1")
+	writeFile(t, certFile, certPEM, modTime)
+	writeFile(t, keyFile, keyPEM, modTime)
+
+	registry, err := schemaregistry.New(ts.URL, schemaregistry.WithTLS(schemaregistry.TLSConfig{
+		CAFile:   caFile,
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	}), schemaregistry.WithRetry(schemaregistry.RetryPolicy{MaxAttempts: 1}))
+	require.Nil(t, err)
+
+	subjects, err := registry.Subjects()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"client-1"}, subjects)
+
+	// rotated client certificate
+	modTime = modTime.Add(time.Minute)
+	certPEM, keyPEM = clientCA.issue(t, "client-2")
+	writeFile(t, certFile, certPEM, modTime)
+	writeFile(t, keyFile, keyPEM, modTime)
+
+	subjects, err = registry.Subjects()
+	require.Nil(t, err)
+	assert.Equal(t, []string{"client-2"}, subjects)
+
+	// rotated CA bundle, not trusting the registry anymore
+	modTime = modTime.Add(time.Minute)
+	writeFile(t, caFile, newTestCA(t, "other-ca").pem, modTime)
+
+	_, err = registry.Subjects()
+	assert.Error(t, err)
+}
+
+func TestTLS_InvalidConfig(t *testing.T) {
+	t.Parallel()
+	ca := newTestCA(t, "registry-ca")
+	certPEM, _ := ca.issue(t, "frames-service")
+
+	configs := map[string]schemaregistry.TLSConfig{
+		"invalid CA":       {CAPEM: []byte("not a certificate")},
+		"missing CA file":  {CAFile: "/nonexistent/ca.pem"},
+		"missing key":      {CertPEM: certPEM},
+		"missing key file": {CertFile: "/nonexistent/client.pem"},
+		"mismatched key":   {CertPEM: certPEM, KeyPEM: ca.pem},
+	}
+	for name, config := range configs {
+		_, err := schemaregistry.New("https://localhost:8081", schemaregistry.WithTLS(config))
+		assert.Error(t, err, name)
+	}
+
+	_, err := schemaregistry.New("https://localhost:8081",
+		schemaregistry.WithHTTPClient(&http.Client{Transport: http.NewFileTransport(http.Dir("."))}),
+		schemaregistry.WithTLS(schemaregistry.TLSConfig{CAPEM: ca.pem}))
+	assert.Error(t, err)
+}
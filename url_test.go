@@ -0,0 +1,64 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_EscapedSubjects(t *testing.T) {
+	t.Parallel()
+	subjects := map[string]string{
+		"orders/v1-value":        "/subjects/orders%2Fv1-value/versions",
+		"100%-value":             "/subjects/100%25-value/versions",
+		"my orders-value":        "/subjects/my%20orders-value/versions",
+		":.staging:orders-value": "/subjects/:.staging:orders-value/versions",
+		"com.acme.orders-value":  "/subjects/com.acme.orders-value/versions",
+	}
+	for subject, path := range subjects {
+		subject, path := subject, path
+		t.Run(subject, func(t *testing.T) {
+			t.Parallel()
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, path, r.URL.EscapedPath())
+				json.NewEncoder(w).Encode(map[string]interface{}{"id": 1})
+			}))
+			defer ts.Close()
+
+			registry, err := schemaregistry.New(ts.URL)
+			require.Nil(t, err)
+
+			id, err := registry.RegisterSubjectSchema(subject, testSchema)
+			require.Nil(t, err)
+			assert.Equal(t, 1, id)
+		})
+	}
+}
+
+func TestRegistry_EndpointWithBasePath(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/registry/subjects/orders%2Fv1-value/versions/latest", r.URL.EscapedPath())
+		json.NewEncoder(w).Encode(schemaregistry.SubjectSchema{
+			Subject: "orders/v1-value",
+			ID:      1,
+			Version: 1,
+			Schema:  testSchema,
+		})
+	}))
+	defer ts.Close()
+
+	for _, endpoint := range []string{ts.URL + "/registry", ts.URL + "/registry/"} {
+		registry, err := schemaregistry.New(endpoint)
+		require.Nil(t, err)
+
+		ss, err := registry.SubjectVersion("orders/v1-value", schemaregistry.Latest)
+		require.Nil(t, err)
+		assert.Equal(t, "orders/v1-value", ss.Subject)
+	}
+}
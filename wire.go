@@ -0,0 +1,73 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// MagicByte is the first byte of the messages in the Confluent wire format.
+const MagicByte byte = 0
+
+// WireHeaderSize is the size of the header of the messages in the Confluent wire format: the magic byte and the
+// 4-byte big-endian schema id.
+const WireHeaderSize = 5
+
+// ErrTruncatedMessage is returned when parsing a message shorter than the wire format header.
+var ErrTruncatedMessage = errors.New("truncated message: shorter than the wire format header")
+
+// UnknownMagicByteError is returned when parsing a message with a magic byte other than MagicByte.
+type UnknownMagicByteError struct {
+	MagicByte byte
+}
+
+func (e UnknownMagicByteError) Error() string {
+	return fmt.Sprintf("unknown magic byte: %d", e.MagicByte)
+}
+
+// AppendWireHeader appends the wire format header of a message of the schema id to dst, returning the extended
+// slice. The payload is meant to be appended after it. It doesn't allocate if dst has enough capacity.
+func AppendWireHeader(dst []byte, id int) []byte {
+	return append(dst, MagicByte, byte(id>>24), byte(id>>16), byte(id>>8), byte(id))
+}
+
+// ParseWire parses a message in the wire format, returning the schema id and the payload. The payload shares the
+// memory of message. It never allocates: the errors are ErrTruncatedMessage and UnknownMagicByteError.
+func ParseWire(message []byte) (id int, payload []byte, err error) {
+	if len(message) < WireHeaderSize {
+		return 0, nil, ErrTruncatedMessage
+	}
+	if message[0] != MagicByte {
+		return 0, nil, UnknownMagicByteError{MagicByte: message[0]}
+	}
+	return int(int32(binary.BigEndian.Uint32(message[1:WireHeaderSize]))), message[WireHeaderSize:], nil
+}
+
+// WireSchema parses a message in the wire format, and resolves its writer schema with registry.
+func WireSchema(registry Registry, message []byte) (schema string, payload []byte, err error) {
+	id, payload, err := ParseWire(message)
+	if err != nil {
+		return "", nil, err
+	}
+	schema, err = registry.Schema(id)
+	if err != nil {
+		return "", nil, err
+	}
+	return schema, payload, nil
+}
+
+// WireSchemaContext is like WireSchema, with a context-aware registry.
+func WireSchemaContext(ctx context.Context, registry ContextRegistry, message []byte) (schema string,
+	payload []byte, err error) {
+	id, payload, err := ParseWire(message)
+	if err != nil {
+		return "", nil, err
+	}
+	schema, err = registry.SchemaContext(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+	return schema, payload, nil
+}
@@ -0,0 +1,92 @@
+package schemaregistry_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/larixsource/go-schema-registry"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWire_RoundTrip(t *testing.T) {
+	for _, id := range []int{0, 1, 255, 256, 70000, 1<<31 - 1} {
+		message := schemaregistry.AppendWireHeader(nil, id)
+		message = append(message, "payload"...)
+		require.Len(t, message, schemaregistry.WireHeaderSize+len("payload"))
+
+		parsedID, payload, err := schemaregistry.ParseWire(message)
+		require.Nil(t, err)
+		assert.Equal(t, id, parsedID)
+		assert.Equal(t, "payload", string(payload))
+	}
+}
+
+func TestWire_Layout(t *testing.T) {
+	message := schemaregistry.AppendWireHeader([]byte{}, 0x01020304)
+	assert.Equal(t, []byte{0, 1, 2, 3, 4}, message)
+
+	id, payload, err := schemaregistry.ParseWire(message)
+	require.Nil(t, err)
+	assert.Equal(t, 0x01020304, id)
+	assert.Empty(t, payload)
+}
+
+func TestWire_Errors(t *testing.T) {
+	_, _, err := schemaregistry.ParseWire([]byte{0, 0, 0})
+	assert.Equal(t, schemaregistry.ErrTruncatedMessage, err)
+
+	_, _, err = schemaregistry.ParseWire(nil)
+	assert.Equal(t, schemaregistry.ErrTruncatedMessage, err)
+
+	_, _, err = schemaregistry.ParseWire([]byte{'{', '"', 'a', '"', ':', '1', '}'})
+	var magicErr schemaregistry.UnknownMagicByteError
+	require.True(t, errors.As(err, &magicErr))
+	assert.Equal(t, byte('{'), magicErr.MagicByte)
+}
+
+func TestWire_NoAllocations(t *testing.T) {
+	buf := make([]byte, 0, 64)
+	allocs := testing.AllocsPerRun(100, func() {
+		message := schemaregistry.AppendWireHeader(buf[:0], 42)
+		message = append(message, "payload"...)
+		_, _, err := schemaregistry.ParseWire(message)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, err = schemaregistry.ParseWire(message[:3])
+		if err == nil {
+			t.Fatal("expected error")
+		}
+		message[0] = 1
+		_, _, err = schemaregistry.ParseWire(message)
+		if err == nil {
+			t.Fatal("expected error")
+		}
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestWire_Schema(t *testing.T) {
+	t.Parallel()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/schemas/ids/42", r.URL.String())
+		json.NewEncoder(w).Encode(map[string]interface{}{"schema": testSchema})
+	}))
+	defer ts.Close()
+
+	registry, err := schemaregistry.NewContextRegistry(ts.URL)
+	require.Nil(t, err)
+
+	message := append(schemaregistry.AppendWireHeader(nil, 42), 0x06, 'a', 'b', 'c')
+	schema, payload, err := schemaregistry.WireSchema(registry, message)
+	require.Nil(t, err)
+	assert.Equal(t, testSchema, schema)
+	assert.Equal(t, []byte{0x06, 'a', 'b', 'c'}, payload)
+
+	_, _, err = schemaregistry.WireSchema(registry, message[:2])
+	assert.Equal(t, schemaregistry.ErrTruncatedMessage, err)
+}